@@ -2,12 +2,18 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/svengiegerich/heidelberg-run/internal/backup"
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/option"
 )
@@ -15,7 +21,11 @@ import (
 const (
 	usage = `USAGE: %s [OPTIONS...]
 
-	Download Google Sheet as an ODS file.
+Backs up the Google Sheet to DIR/YYYY-MM-DD/ as ODS export (sheet.ods), CSV file per sheet (csv/) and
+normalised JSON (data.json), verifies the new backup by parsing it back and prunes old backups (daily backups
+are kept for -keepdays days, older ones only the first backup of each month).
+With -verify, only verifies an existing backup directory.
+To preview the site generated from a backup, run 'go run cmd/serve/main.go -backup DIR/YYYY-MM-DD'.
 
 OPTIONS:
 `
@@ -23,12 +33,20 @@ OPTIONS:
 
 type CommandLineOptions struct {
 	configFile string
-	outputFile string
+	backupDir  string
+	formats    string
+	baseUrl    string
+	keepDays   int
+	verifyDir  string
 }
 
 func parseCommandLine() CommandLineOptions {
 	configFile := flag.String("config", "", "Config file")
-	outputFile := flag.String("output", "", "Output file")
+	backupDir := flag.String("dir", "backup-data", "backup directory")
+	formats := flag.String("formats", "ods,csv,json", "comma separated backup formats (ods, csv, json)")
+	baseUrl := flag.String("baseurl", "https://heidelberg.run", "base URL of the site (for the URLs in the JSON)")
+	keepDays := flag.Int("keepdays", 30, "keep daily backups for this many days, then monthly (0: never prune)")
+	verifyDir := flag.String("verify", "", "only verify the backup in this directory")
 
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
@@ -36,73 +54,171 @@ func parseCommandLine() CommandLineOptions {
 	}
 	flag.Parse()
 
-	if *configFile == "" || *outputFile == "" {
+	if *configFile == "" && *verifyDir == "" {
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	return CommandLineOptions{
 		*configFile,
-		*outputFile,
+		*backupDir,
+		*formats,
+		*baseUrl,
+		*keepDays,
+		*verifyDir,
 	}
 }
 
-type ConfigData struct {
-	ApiKey  string `json:"api_key"`
-	SheetId string `json:"sheet_id"`
+func parseFormats(s string) (map[string]bool, error) {
+	formats := make(map[string]bool)
+	for _, format := range strings.Split(s, ",") {
+		format = strings.TrimSpace(format)
+		switch format {
+		case "ods", "csv", "json":
+			formats[format] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown backup format '%s'", format)
+		}
+	}
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("no backup format selected")
+	}
+	return formats, nil
+}
+
+func exportOds(config events.SheetsConfigData, fileName string) error {
+	ctx := context.Background()
+	service, err := drive.NewService(ctx, option.WithAPIKey(config.ApiKey))
+	if err != nil {
+		return fmt.Errorf("connect to Google Drive: %w", err)
+	}
+
+	response, err := service.Files.Export(config.SheetId, "application/vnd.oasis.opendocument.spreadsheet").Download()
+	if err != nil {
+		return fmt.Errorf("download file: %w", err)
+	}
+	defer response.Body.Close()
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	if _, err := io.Copy(file, response.Body); err != nil {
+		file.Close()
+		return fmt.Errorf("write output file: %w", err)
+	}
+	return file.Close()
 }
 
-func UnmarshalConfigData(path string) (ConfigData, error) {
-	buf, err := os.ReadFile(path)
+func verify(dir string) {
+	date, err := backup.SourceDate(dir)
 	if err != nil {
-		return ConfigData{}, fmt.Errorf("read config file '%s': %w", path, err)
+		log.Fatal(err)
 	}
-	var data ConfigData
-	err = json.Unmarshal(buf, &data)
+	summary, err := backup.Verify(dir, nil, date)
 	if err != nil {
-		return ConfigData{}, fmt.Errorf("unmarshall data: %w", err)
+		log.Fatalf("backup is broken: %v", err)
+	}
+	fmt.Printf("-- backup %s is ok: %v\n", dir, summary)
+}
+
+// create writes the backup files of the selected formats to dir and verifies them.
+func create(config events.SheetsConfigData, formats map[string]bool, baseUrl string, dir string, today time.Time) (backup.Summary, error) {
+	if formats["ods"] {
+		fmt.Printf("-- exporting %s to %s...\n", config.SheetId, filepath.Join(dir, backup.OdsFile))
+		if err := exportOds(config, filepath.Join(dir, backup.OdsFile)); err != nil {
+			return backup.Summary{}, fmt.Errorf("export ods: %w", err)
+		}
 	}
 
-	return data, nil
+	var snapshot *events.Snapshot
+	if formats["csv"] || formats["json"] {
+		fmt.Printf("-- fetching tables of %s...\n", config.SheetId)
+		src, err := events.NewSheetsSource(config)
+		if err != nil {
+			return backup.Summary{}, fmt.Errorf("connect to Google Sheets: %w", err)
+		}
+		if snapshot, err = events.TakeSnapshot(src); err != nil {
+			return backup.Summary{}, fmt.Errorf("fetch tables: %w", err)
+		}
+	}
+	if formats["csv"] {
+		fmt.Printf("-- saving csv files to %s...\n", filepath.Join(dir, backup.CsvDir))
+		if err := backup.WriteCsv(filepath.Join(dir, backup.CsvDir), snapshot); err != nil {
+			return backup.Summary{}, fmt.Errorf("write csv files: %w", err)
+		}
+	}
+	if formats["json"] {
+		fmt.Printf("-- saving normalised data to %s...\n", filepath.Join(dir, backup.JsonFile))
+		data, err := events.FetchDataFrom(snapshot, today, nil) // the sheet's tags only, no region tags
+		if err != nil {
+			return backup.Summary{}, fmt.Errorf("parse tables: %w", err)
+		}
+		normalized := backup.Normalize(data, utils.Url(baseUrl), today)
+		if err := backup.WriteJson(filepath.Join(dir, backup.JsonFile), normalized); err != nil {
+			return backup.Summary{}, fmt.Errorf("write json: %w", err)
+		}
+	}
+
+	fmt.Println("-- verifying backup...")
+	return backup.Verify(dir, snapshot, today)
 }
 
 func main() {
 	options := parseCommandLine()
 
-	config, err := UnmarshalConfigData(options.configFile)
-	if err != nil {
-		fmt.Printf("Unable to read config file: %v\n", err)
+	if options.verifyDir != "" {
+		verify(options.verifyDir)
 		return
 	}
 
-	fmt.Println("-- connecting to Google Drive service...")
-	ctx := context.Background()
-	service, err := drive.NewService(ctx, option.WithAPIKey(config.ApiKey))
+	formats, err := parseFormats(options.formats)
 	if err != nil {
-		fmt.Printf("Unable to connect to Google Drive: %v\n", err)
-		return
+		log.Fatalf("bad -formats: %v", err)
 	}
-
-	fmt.Printf("-- requesting file %s...\n", config.SheetId)
-	response, err := service.Files.Export(config.SheetId, "application/vnd.oasis.opendocument.spreadsheet").Download()
+	config, err := events.LoadSheetsConfig(options.configFile)
 	if err != nil {
-		fmt.Printf("Unable to download file: %v\n", err)
-		return
+		log.Fatalf("unable to read config file: %v", err)
 	}
-	defer response.Body.Close()
 
-	fmt.Printf("-- saving to %s...\n", options.outputFile)
-	file, err := os.Create(options.outputFile)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	name := today.Format(backup.DateFormat)
+	if err := os.MkdirAll(options.backupDir, 0770); err != nil {
+		log.Fatalf("unable to create backup directory: %v", err)
+	}
+	// the new backup replaces an existing backup of today only once it is verified; the temporary directory is
+	// ignored by backup.List (it is not named YYYY-MM-DD)
+	tmpDir, err := os.MkdirTemp(options.backupDir, "."+name+"-")
 	if err != nil {
-		fmt.Printf("Unable to create output file: %v\n", err)
-		return
+		log.Fatalf("unable to create temporary backup directory: %v", err)
 	}
-	defer file.Close()
-
-	_, err = io.Copy(file, response.Body)
+	if err := os.Chmod(tmpDir, 0770); err != nil {
+		log.Fatalf("unable to create temporary backup directory: %v", err)
+	}
+	summary, err := create(config, formats, options.baseUrl, tmpDir, today)
 	if err != nil {
-		fmt.Printf("Unable to write to output file: %v\n", err)
-		return
+		os.RemoveAll(tmpDir)
+		log.Fatalf("new backup is broken (existing backups are kept): %v", err)
+	}
+	dir := filepath.Join(options.backupDir, name)
+	if err := os.RemoveAll(dir); err != nil {
+		log.Fatalf("unable to replace today's backup (the new one is in %s): %v", tmpDir, err)
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		log.Fatalf("unable to move the new backup to %s (it is in %s): %v", dir, tmpDir, err)
+	}
+	fmt.Printf("-- backup %s ok: %v\n", dir, summary)
+
+	if options.keepDays > 0 {
+		removed, err := backup.Prune(options.backupDir, now, options.keepDays)
+		for _, name := range removed {
+			fmt.Printf("-- pruned %s\n", name)
+		}
+		if err != nil {
+			log.Fatalf("unable to prune old backups: %v", err)
+		}
 	}
 
 	fmt.Println("-- done")
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/printcal"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+const (
+	usage = `USAGE: %s [OPTIONS...]
+
+	Render upcoming events as a printable PDF calendar.
+
+OPTIONS:
+`
+)
+
+type CommandLineOptions struct {
+	configFile string
+	outputFile string
+	pageSize   string
+	tag        string
+	serie      string
+	regionsDir string
+}
+
+func parseCommandLine() CommandLineOptions {
+	configFile := flag.String("config", "", "select config file")
+	outputFile := flag.String("output", "kalender.pdf", "output file")
+	pageSize := flag.String("size", "A4", "page size (A4 or A5)")
+	tag := flag.String("tag", "", "only include events with this tag")
+	serie := flag.String("serie", "", "only include events of this series")
+	regionsDir := flag.String("regions", "", "directory of region boundaries (*.geojson) for automatic region tags")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *configFile == "" || (*tag != "" && *serie != "") {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	return CommandLineOptions{
+		*configFile,
+		*outputFile,
+		*pageSize,
+		*tag,
+		*serie,
+		*regionsDir,
+	}
+}
+
+func selectEvents(data events.Data, tagName, serieName string) ([]*events.Event, string, error) {
+	if tagName != "" {
+		sanitized := utils.SanitizeName(tagName)
+		for _, tag := range data.Tags {
+			if tag.Name.Sanitized == sanitized {
+				return tag.Events, fmt.Sprintf("Kategorie '%s'", tag.Name.Orig), nil
+			}
+		}
+		return nil, "", fmt.Errorf("unknown tag '%s'", tagName)
+	}
+	if serieName != "" {
+		sanitized := utils.SanitizeName(serieName)
+		for _, serie := range data.Series {
+			if serie.Name.Sanitized == sanitized {
+				return serie.Events, fmt.Sprintf("Serie '%s'", serie.Name.Orig), nil
+			}
+		}
+		return nil, "", fmt.Errorf("unknown series '%s'", serieName)
+	}
+	return data.Events, "", nil
+}
+
+func main() {
+	options := parseCommandLine()
+
+	config, err := events.LoadSheetsConfig(options.configFile)
+	if err != nil {
+		log.Fatalf("failed to load config file: %v", err)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	regions, err := events.LoadRegions(options.regionsDir)
+	if err != nil {
+		log.Fatalf("failed to load regions: %v", err)
+	}
+	data, err := utils.Retry(3, 8*time.Second, func() (events.Data, error) {
+		return events.FetchData(config, today, regions)
+	})
+	if err != nil {
+		log.Fatalf("failed to fetch data: %v", err)
+	}
+
+	eventList, filter, err := selectEvents(data, options.tag, options.serie)
+	if err != nil {
+		log.Fatalf("failed to select events: %v", err)
+	}
+
+	subtitle := "Laufveranstaltungen im Raum Heidelberg - alle Infos und Anmeldelinks per QR-Code"
+	if filter != "" {
+		subtitle = fmt.Sprintf("%s - %s", filter, subtitle)
+	}
+
+	err = printcal.Render(eventList, printcal.Options{
+		Title:    fmt.Sprintf("Laufkalender %d", today.Year()),
+		Subtitle: subtitle,
+		PageSize: options.pageSize,
+		BaseUrl:  utils.Url("https://heidelberg.run"),
+		Now:      now,
+	}, options.outputFile)
+	if err != nil {
+		log.Fatalf("failed to render calendar: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/sitecheck"
+)
+
+const (
+	usage = `USAGE: %s [OPTIONS...]
+
+Checks the internal links and assets of the generated site (offline): reports references that resolve neither
+to a file of the output directory nor to a redirect rule, and pages no other page links to.
+Exits with status 1 if dangling references (or, with -strict, orphan pages) are found.
+
+OPTIONS:
+`
+)
+
+type CommandLineOptions struct {
+	outDir  string
+	baseUrl string
+	ignore  string
+	allow   string
+	strict  bool
+}
+
+func parseCommandLine() CommandLineOptions {
+	outDir := flag.String("out", ".out", "output directory of the generated site")
+	baseUrl := flag.String("baseurl", "https://heidelberg.run", "base URL of the site (absolute links to it count as internal)")
+	ignore := flag.String("ignore", "embed/*", "comma separated patterns of pages that may be orphans")
+	allow := flag.String("allow", "", "comma separated patterns of paths that may dangle (known gaps)")
+	strict := flag.Bool("strict", false, "fail on orphan pages, too")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	return CommandLineOptions{
+		*outDir,
+		*baseUrl,
+		*ignore,
+		*allow,
+		*strict,
+	}
+}
+
+func splitPatterns(s string) []string {
+	patterns := make([]string, 0)
+	for _, pattern := range strings.Split(s, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+func main() {
+	options := parseCommandLine()
+
+	checker, err := sitecheck.NewChecker(options.outDir, options.baseUrl, splitPatterns(options.ignore), splitPatterns(options.allow))
+	if err != nil {
+		log.Fatalf("failed to create checker: %v", err)
+	}
+	report, err := checker.Check()
+	if err != nil {
+		log.Fatalf("failed to check site: %v", err)
+	}
+
+	for _, dangling := range report.Dangling {
+		fmt.Printf("dangling: %s -> %s\n", dangling.Page, dangling.Ref)
+	}
+	for _, orphan := range report.Orphans {
+		fmt.Printf("orphan: %s\n", orphan)
+	}
+	fmt.Printf("%d pages, %d internal references, %d dangling, %d orphans\n", report.Pages, report.Refs, len(report.Dangling), len(report.Orphans))
+
+	if len(report.Dangling) > 0 || (options.strict && len(report.Orphans) > 0) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/backup"
+	"github.com/svengiegerich/heidelberg-run/internal/diff"
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+)
+
+const (
+	usage = `USAGE: %s [OPTIONS...] OLD NEW
+
+Compares two versions of the spreadsheet data semantically: added, removed and renamed entries, changed fields
+(date, place, status, links, ...) and tag and series membership.
+OLD and NEW are each one of
+  sheet                     the live Google Sheet (needs -config)
+  backup-data/YYYY-MM-DD    a backup directory (CSV tables, or sheet.ods)
+  FILE.ods                  an ODS export (e.g. an old backup)
+  FILE.json                 a data snapshot (e.g. .data-snapshot.json)
+
+OPTIONS:
+`
+)
+
+type CommandLineOptions struct {
+	configFile string
+	slugsFile  string
+	format     string
+	today      string
+	regionsDir string
+	oldSource  string
+	newSource  string
+}
+
+func parseCommandLine() CommandLineOptions {
+	configFile := flag.String("config", "", "config file (needed for 'sheet')")
+	slugsFile := flag.String("slugs", "slugs.json", "slug registry file (to match renamed entries)")
+	format := flag.String("format", "text", "output format: text, markdown or json")
+	today := flag.String("today", "", "date (YYYY-MM-DD) splitting upcoming and past events (default: today)")
+	regionsDir := flag.String("regions", "", "directory of region boundaries (*.geojson) for automatic region tags")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	return CommandLineOptions{
+		*configFile,
+		*slugsFile,
+		*format,
+		*today,
+		*regionsDir,
+		flag.Arg(0),
+		flag.Arg(1),
+	}
+}
+
+func loadSnapshot(source string, configFile string) (*events.Snapshot, error) {
+	if source == "sheet" {
+		if configFile == "" {
+			return nil, fmt.Errorf("you have to specify a config file to read the live sheet, e.g. -config myconfig.json")
+		}
+		config, err := events.LoadSheetsConfig(configFile)
+		if err != nil {
+			return nil, err
+		}
+		src, err := events.NewSheetsSource(config)
+		if err != nil {
+			return nil, err
+		}
+		return events.TakeSnapshot(src)
+	}
+
+	return backup.LoadSource(source)
+}
+
+func loadData(source string, configFile string, regions []*events.Region, today time.Time) (events.Data, error) {
+	snapshot, err := loadSnapshot(source, configFile)
+	if err != nil {
+		return events.Data{}, fmt.Errorf("load '%s': %w", source, err)
+	}
+	data, err := events.FetchDataFrom(snapshot, today, regions)
+	if err != nil {
+		return events.Data{}, fmt.Errorf("parse '%s': %w", source, err)
+	}
+	return data, nil
+}
+
+func main() {
+	options := parseCommandLine()
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if options.today != "" {
+		t, err := time.ParseInLocation("2006-01-02", options.today, now.Location())
+		if err != nil {
+			log.Fatalf("bad -today: %v", err)
+		}
+		today = t
+	}
+
+	regions, err := events.LoadRegions(options.regionsDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	oldData, err := loadData(options.oldSource, options.configFile, regions, today)
+	if err != nil {
+		log.Fatal(err)
+	}
+	newData, err := loadData(options.newSource, options.configFile, regions, today)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registry, err := events.LoadSlugRegistry(options.slugsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result := diff.Compare(oldData, newData, registry)
+	switch options.format {
+	case "text":
+		err = result.WriteText(os.Stdout)
+	case "markdown", "md":
+		err = result.WriteMarkdown(os.Stdout)
+	case "json":
+		err = result.WriteJson(os.Stdout)
+	default:
+		log.Fatalf("unknown output format '%s'", options.format)
+	}
+	if err != nil {
+		log.Fatalf("failed to write diff: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/digest"
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+const (
+	usage = `USAGE: %s [OPTIONS...]
+
+	Build the email digest of upcoming events and send it via SMTP
+	(or write it to a file with -dry-run).
+
+OPTIONS:
+`
+)
+
+type CommandLineOptions struct {
+	configFile   string
+	smtpFile     string
+	stateFile    string
+	days         int
+	deadlineDays int
+	dryRun       string
+}
+
+func parseCommandLine() CommandLineOptions {
+	configFile := flag.String("config", "", "select config file")
+	smtpFile := flag.String("smtp", "smtp.json", "smtp config file (host, port, username, password, from, to)")
+	stateFile := flag.String("state", ".digest-state.json", "file storing the events known at the last digest")
+	days := flag.Int("days", 7, "include events of the next N days")
+	deadlineDays := flag.Int("deadline-days", 14, "include registration deadlines of the next N days")
+	dryRun := flag.String("dry-run", "", "write the email to this file instead of sending it")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *configFile == "" || *days <= 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	return CommandLineOptions{
+		*configFile,
+		*smtpFile,
+		*stateFile,
+		*days,
+		*deadlineDays,
+		*dryRun,
+	}
+}
+
+func main() {
+	options := parseCommandLine()
+
+	config, err := events.LoadSheetsConfig(options.configFile)
+	if err != nil {
+		log.Fatalf("failed to load config file: %v", err)
+	}
+
+	var smtpConfig digest.SmtpConfig
+	if options.dryRun == "" {
+		if smtpConfig, err = digest.LoadSmtpConfig(options.smtpFile); err != nil {
+			log.Fatalf("failed to load smtp config: %v", err)
+		}
+	} else {
+		smtpConfig.From = "digest@heidelberg.run"
+		smtpConfig.To = []string{"dry-run@heidelberg.run"}
+	}
+
+	state, err := digest.LoadState(options.stateFile)
+	if err != nil {
+		log.Fatalf("failed to load state: %v", err)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	data, err := utils.Retry(3, 8*time.Second, func() (events.Data, error) {
+		return events.FetchData(config, today, nil)
+	})
+	if err != nil {
+		log.Fatalf("failed to fetch data: %v", err)
+	}
+
+	d := digest.Build(data, state, digest.Options{
+		Now:          now,
+		Days:         options.days,
+		DeadlineDays: options.deadlineDays,
+		BaseUrl:      utils.Url("https://heidelberg.run"),
+	})
+	html, err := d.Html()
+	if err != nil {
+		log.Fatalf("failed to create digest: %v", err)
+	}
+	text, err := d.Text()
+	if err != nil {
+		log.Fatalf("failed to create digest: %v", err)
+	}
+	message, err := digest.Message(smtpConfig.From, d.Subject, text, html, now)
+	if err != nil {
+		log.Fatalf("failed to create digest: %v", err)
+	}
+
+	if options.dryRun != "" {
+		if err := os.WriteFile(options.dryRun, message, 0o644); err != nil {
+			log.Fatalf("failed to write digest: %v", err)
+		}
+		log.Printf("wrote digest to %s", options.dryRun)
+		return
+	}
+
+	if err := digest.Send(smtpConfig, message); err != nil {
+		log.Fatalf("failed to send digest: %v", err)
+	}
+	if err := digest.SaveState(options.stateFile, data); err != nil {
+		log.Fatalf("failed to save state: %v", err)
+	}
+	log.Printf("sent digest to %d recipients", len(smtpConfig.To))
+}
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"runtime"
 	"time"
 
 	"github.com/svengiegerich/heidelberg-run/internal/events"
 	"github.com/svengiegerich/heidelberg-run/internal/generator"
-	"github.com/svengiegerich/heidelberg-run/internal/resources"
 	"github.com/svengiegerich/heidelberg-run/internal/utils"
 )
 
@@ -21,19 +21,37 @@ OPTIONS:
 )
 
 type CommandLineOptions struct {
-	configFile string
-	outDir     string
-	hashFile   string
-	checkLinks bool
-	basePath   string
+	configFile    string
+	outDir        string
+	hashFile      string
+	slugsFile     string
+	changelogFile string
+	regionsDir    string
+	checkLinks    bool
+	linkHistory   string
+	linkReport    string
+	linkFailures  int
+	imageCache    string
+	embedsFile    string
+	assetManifest string
+	jobs          int
 }
 
 func parseCommandLine() CommandLineOptions {
 	configFile := flag.String("config", "", "select config file")
 	outDir := flag.String("out", ".out", "output directory")
-	hashFile := flag.String("hashfile", ".hashes", "file storing file hashes (for sitemap)")
+	hashFile := flag.String("hashfile", ".hashes", "file storing page hashes by slug (for sitemap lastmod)")
+	slugsFile := flag.String("slugs", "slugs.json", "slug registry file (former slugs of all entries, for redirects)")
+	changelogFile := flag.String("changelog", "changelog.json", "changelog file (entry states of the previous build and recent changes)")
+	regionsDir := flag.String("regions", "", "directory of region boundaries (*.geojson) for automatic region tags")
 	checkLinks := flag.Bool("checklinks", false, "check links in the generated files")
-	basePath := flag.String("basepath", "", "base path")
+	linkHistory := flag.String("linkhistory", ".linkhistory.json", "file keeping the link check results across runs (with -checklinks)")
+	linkReport := flag.String("linkreport", ".linkreport", "directory for the link check report (with -checklinks)")
+	linkFailures := flag.Int("linkfailures", 3, "number of consecutive failed runs before a link is flagged (with -checklinks)")
+	imageCache := flag.String("imagecache", ".imagecache", "directory caching generated share images")
+	embedsFile := flag.String("embeds", "embeds.json", "embeddable event lists config file")
+	assetManifest := flag.String("assets", "assets.json", "asset manifest file")
+	jobs := flag.Int("jobs", runtime.NumCPU(), "number of pages rendered in parallel")
 
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
@@ -49,8 +67,17 @@ func parseCommandLine() CommandLineOptions {
 		*configFile,
 		*outDir,
 		*hashFile,
+		*slugsFile,
+		*changelogFile,
+		*regionsDir,
 		*checkLinks,
-		*basePath,
+		*linkHistory,
+		*linkReport,
+		*linkFailures,
+		*imageCache,
+		*embedsFile,
+		*assetManifest,
+		*jobs,
 	}
 }
 
@@ -59,6 +86,27 @@ type ConfigData struct {
 	SheetId string `json:"sheet_id"`
 }
 
+func checkLinks(eventsData events.Data, options CommandLineOptions, now time.Time) {
+	history, err := utils.LoadLinkHistory(options.linkHistory)
+	if err != nil {
+		log.Fatalf("failed to load link history: %v", err)
+	}
+	report := eventsData.CheckLinks(utils.NewLinkChecker(), history, options.linkFailures, now)
+	if err := history.Save(options.linkHistory); err != nil {
+		log.Fatalf("failed to save link history: %v", err)
+	}
+	if err := report.Write(options.linkReport); err != nil {
+		log.Fatalf("failed to write link report: %v", err)
+	}
+
+	for _, result := range report.Results {
+		if result.Flagged {
+			fmt.Printf("Invalid %s link in %s '%s' (failed %d times): %s -> %s\n", result.Kind, result.Type, result.Name, result.Failures, result.Url, result.Error)
+		}
+	}
+	fmt.Printf("%d links checked, %d failed, %d flagged; report: %s\n", report.Checked, report.Failed, report.Flagged, options.linkReport)
+}
+
 func main() {
 	options := parseCommandLine()
 
@@ -71,45 +119,83 @@ func main() {
 	// configuration
 	out := utils.NewPath(options.outDir)
 	baseUrl := utils.Url("https://heidelberg.run")
-	basePath := options.basePath
 	sheetUrl := fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s", config_data.SheetId)
 	umamiId := "a07dea4a-0187-4121-8869-dd43dd1762a4"
 	feedbackFormUrl := "https://forms.gle/8LrkM7J65G3mqV4B7"
 	now := time.Now()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
+	embeds, err := generator.LoadEmbedConfig(options.embedsFile)
+	if err != nil {
+		log.Fatalf("failed to load embeds config: %v", err)
+		return
+	}
+
+	redirects, err := generator.LoadRedirectConfig(options.configFile)
+	if err != nil {
+		log.Fatalf("failed to load redirect config: %v", err)
+		return
+	}
+
+	nearby, err := events.LoadNearbyConfig(options.configFile)
+	if err != nil {
+		log.Fatalf("failed to load nearby config: %v", err)
+		return
+	}
+
+	regions, err := events.LoadRegions(options.regionsDir)
+	if err != nil {
+		log.Fatalf("failed to load regions: %v", err)
+		return
+	}
+
 	// try 3 times to fetch data with increasing timeouts (sometimes the google api is not available)
 	eventsData, err := utils.Retry(3, 8*time.Second, func() (events.Data, error) {
-		return events.FetchData(config_data, today)
+		return events.FetchData(config_data, today, regions)
 	})
 	if err != nil {
 		log.Fatalf("failed to fetch data: %v", err)
 		return
 	}
+	eventsData.FindNearby(nearby, today)
 
 	if options.checkLinks {
-		eventsData.CheckLinks()
+		checkLinks(eventsData, options, now)
 		return
 	}
 
-	resourceManager := resources.NewResourceManager(".", string(out))
-	resourceManager.CopyExternalAssets()
-	if resourceManager.Error != nil {
-		log.Fatalf("failed to copy external assets: %v", resourceManager.Error)
+	slugs, err := events.LoadSlugRegistry(options.slugsFile)
+	if err != nil {
+		log.Fatalf("failed to load slug registry: %v", err)
+	}
+	if err := slugs.Update(&eventsData); err != nil {
+		log.Fatalf("failed to update slug registry: %v", err)
+	}
+	if err := slugs.Save(options.slugsFile); err != nil {
+		log.Fatalf("failed to save slug registry: %v", err)
+	}
+
+	changelog, err := events.LoadChangelog(options.changelogFile)
+	if err != nil {
+		log.Fatalf("failed to load changelog: %v", err)
 	}
-	resourceManager.CopyStaticAssets()
-	if resourceManager.Error != nil {
-		log.Fatalf("failed to copy static assets: %v", resourceManager.Error)
+	changelog.Update(&eventsData, today)
+	if err := changelog.Save(options.changelogFile); err != nil {
+		log.Fatalf("failed to save changelog: %v", err)
 	}
 
 	gen := generator.NewGenerator(
 		out,
-		baseUrl, basePath,
+		baseUrl,
 		now,
-		resourceManager.JsFiles, resourceManager.CssFiles,
-		resourceManager.UmamiScript, umamiId,
+		options.assetManifest,
+		umamiId,
 		feedbackFormUrl, sheetUrl,
-		options.hashFile)
+		options.hashFile,
+		options.imageCache,
+		embeds,
+		redirects,
+		options.jobs)
 	if err := gen.Generate(eventsData); err != nil {
 		log.Fatalf("failed to generate: %v", err)
 	}
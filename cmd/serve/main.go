@@ -0,0 +1,513 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/backup"
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/generator"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+const (
+	usage = `USAGE: %s [OPTIONS...]
+
+Serves the generated site locally; regenerates it when one of its inputs (templates, static files, the
+data snapshot, ...) changes and reloads the affected pages in the browser. If only page templates changed,
+only their pages are rendered again.
+With -backup, the site is generated from a backup (restore preview) as of the day the backup was taken.
+
+OPTIONS:
+`
+	liveReloadPath   = "/_livereload"
+	liveReloadScript = `<script>
+(() => {
+    const source = new EventSource("` + liveReloadPath + `");
+    source.onmessage = (e) => {
+        const changed = JSON.parse(e.data);
+        if (changed.some(p => p === location.pathname || p.endsWith(".css") || p.endsWith(".js"))) {
+            location.reload();
+        }
+    };
+})();
+</script>
+`
+)
+
+type CommandLineOptions struct {
+	configFile    string
+	snapshotFile  string
+	backupDir     string
+	slugsFile     string
+	changelogFile string
+	regionsDir    string
+	refresh       bool
+	outDir        string
+	addr          string
+	imageCache    string
+	embedsFile    string
+	assetManifest string
+	jobs          int
+	interval      time.Duration
+}
+
+func parseCommandLine() CommandLineOptions {
+	configFile := flag.String("config", "", "select config file (needed to fetch the data snapshot)")
+	snapshotFile := flag.String("snapshot", ".data-snapshot.json", "data snapshot file (fetched from Google Sheets if missing)")
+	backupDir := flag.String("backup", "", "generate from a backup (directory YYYY-MM-DD with CSV tables or sheet.ods, or legacy YYYY-MM-DD.ods) instead of the snapshot (restore preview)")
+	slugsFile := flag.String("slugs", "slugs.json", "slug registry file (read only)")
+	changelogFile := flag.String("changelog", "changelog.json", "changelog file (read only)")
+	regionsDir := flag.String("regions", "", "directory of region boundaries (*.geojson) for automatic region tags")
+	refresh := flag.Bool("refresh", false, "fetch a fresh data snapshot from Google Sheets")
+	outDir := flag.String("out", ".serve", "output directory (not the one of the production build: the pages link to -addr)")
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	imageCache := flag.String("imagecache", ".imagecache", "directory caching generated share images")
+	embedsFile := flag.String("embeds", "embeds.json", "embeddable event lists config file")
+	assetManifest := flag.String("assets", "assets.json", "asset manifest file")
+	jobs := flag.Int("jobs", 4, "number of pages rendered in parallel")
+	interval := flag.Duration("interval", 500*time.Millisecond, "interval for checking files for changes")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	return CommandLineOptions{
+		*configFile,
+		*snapshotFile,
+		*backupDir,
+		*slugsFile,
+		*changelogFile,
+		*regionsDir,
+		*refresh,
+		*outDir,
+		*addr,
+		*imageCache,
+		*embedsFile,
+		*assetManifest,
+		*jobs,
+		*interval,
+	}
+}
+
+// fetchSnapshot stores a snapshot of the Google Sheets tables, so that the server works offline afterwards.
+func fetchSnapshot(options CommandLineOptions) error {
+	if options.configFile == "" {
+		return fmt.Errorf("you have to specify a config file to fetch the data snapshot, e.g. -config myconfig.json")
+	}
+	config, err := events.LoadSheetsConfig(options.configFile)
+	if err != nil {
+		return err
+	}
+	src, err := events.NewSheetsSource(config)
+	if err != nil {
+		return err
+	}
+	snapshot, err := events.TakeSnapshot(src)
+	if err != nil {
+		return err
+	}
+	return snapshot.Save(options.snapshotFile)
+}
+
+type server struct {
+	options CommandLineOptions
+	out     utils.Path
+
+	mutex    sync.RWMutex // guards htaccess
+	htaccess utils.Htaccess
+
+	clientsMutex sync.Mutex
+	clients      map[chan []string]struct{}
+}
+
+// scanOutput returns the modification times of all output files.
+func (s *server) scanOutput() map[string]time.Time {
+	mtimes := make(map[string]time.Time)
+	filepath.WalkDir(s.out.String(), func(fileName string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			mtimes[fileName] = info.ModTime()
+		}
+		return nil
+	})
+	return mtimes
+}
+
+// build regenerates the site and returns the URL paths of the changed files; changedInputs are the inputs that
+// changed since the last build (nil: render all pages).
+func (s *server) build(changedInputs []string) ([]string, error) {
+	before := s.scanOutput()
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if s.options.backupDir != "" {
+		// upcoming and past events as they were split on the day of the backup
+		date, err := backup.SourceDate(s.options.backupDir)
+		if err != nil {
+			return nil, err
+		}
+		today = date
+	}
+	snapshot, err := s.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	regions, err := events.LoadRegions(s.options.regionsDir)
+	if err != nil {
+		return nil, err
+	}
+	eventsData, err := events.FetchDataFrom(snapshot, today, regions)
+	if err != nil {
+		return nil, fmt.Errorf("load data: %w", err)
+	}
+	nearby := events.DefaultNearbyConfig
+	if s.options.configFile != "" {
+		if nearby, err = events.LoadNearbyConfig(s.options.configFile); err != nil {
+			return nil, err
+		}
+	}
+	eventsData.FindNearby(nearby, today)
+	slugs, err := events.LoadSlugRegistry(s.options.slugsFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := slugs.Update(&eventsData); err != nil {
+		return nil, err
+	}
+	changelog, err := events.LoadChangelog(s.options.changelogFile)
+	if err != nil {
+		return nil, err
+	}
+	changelog.Update(&eventsData, today)
+	embeds, err := generator.LoadEmbedConfig(s.options.embedsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.ResetTemplates()
+	if changedInputs != nil {
+		if names := affectedTemplates(changedInputs); names != nil {
+			utils.RenderOnly(names...)
+			defer utils.RenderOnly()
+		}
+	}
+	gen := generator.NewGenerator(
+		s.out,
+		utils.Url("http://"+s.options.addr),
+		now,
+		s.options.assetManifest,
+		"", // no analytics
+		"", "",
+		s.out.Join(".hashes"),
+		s.options.imageCache,
+		embeds,
+		[]generator.RedirectBackend{generator.RedirectHtaccess}, // the server honours the .htaccess rules
+		s.options.jobs)
+	if err := gen.Generate(eventsData); err != nil {
+		return nil, err
+	}
+
+	htaccess, err := loadHtaccess(s.out.Join(".htaccess"))
+	if err != nil {
+		return nil, err
+	}
+	s.mutex.Lock()
+	s.htaccess = htaccess
+	s.mutex.Unlock()
+
+	changed := make([]string, 0)
+	for fileName, mtime := range s.scanOutput() {
+		if old, ok := before[fileName]; ok && old.Equal(mtime) {
+			continue
+		}
+		rel, err := filepath.Rel(s.out.String(), fileName)
+		if err != nil {
+			continue
+		}
+		urlPath := "/" + filepath.ToSlash(rel)
+		changed = append(changed, urlPath)
+		if dir, file := path.Split(urlPath); file == "index.html" {
+			changed = append(changed, dir)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// loadSnapshot reads the data snapshot or the tables of the backup.
+func (s *server) loadSnapshot() (*events.Snapshot, error) {
+	if s.options.backupDir != "" {
+		return backup.LoadSource(s.options.backupDir)
+	}
+	return events.LoadSnapshot(s.options.snapshotFile)
+}
+
+func loadHtaccess(fileName string) (utils.Htaccess, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return utils.Htaccess{}, err
+	}
+	defer f.Close()
+	return utils.ParseHtaccess(f)
+}
+
+// inputs returns the directories and files the build reads.
+func (s *server) inputs() ([]string, []string) {
+	dirs := []string{"templates", "static"}
+	files := []string{s.options.slugsFile, s.options.changelogFile, s.options.embedsFile, s.options.assetManifest}
+	if s.options.backupDir != "" {
+		dirs = append(dirs, s.options.backupDir)
+	} else {
+		files = append(files, s.options.snapshotFile)
+	}
+	if s.options.regionsDir != "" {
+		dirs = append(dirs, s.options.regionsDir)
+	}
+	if s.options.configFile != "" {
+		files = append(files, s.options.configFile)
+	}
+	return dirs, files
+}
+
+// fingerprint returns the size and modification time of each input file.
+func (s *server) fingerprint() map[string]string {
+	fingerprint := make(map[string]string)
+	add := func(fileName string, info fs.FileInfo) {
+		fingerprint[filepath.Clean(fileName)] = fmt.Sprintf("%d %d", info.Size(), info.ModTime().UnixNano())
+	}
+	dirs, files := s.inputs()
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(fileName string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			if info, err := d.Info(); err == nil {
+				add(fileName, info)
+			}
+			return nil
+		})
+	}
+	for _, fileName := range files {
+		if info, err := os.Stat(fileName); err == nil {
+			add(fileName, info)
+		}
+	}
+	return fingerprint
+}
+
+// changedFiles returns the files added, removed or modified between two fingerprints.
+func changedFiles(before, after map[string]string) []string {
+	changed := make([]string, 0)
+	for fileName, value := range after {
+		if before[fileName] != value {
+			changed = append(changed, fileName)
+		}
+	}
+	for fileName := range before {
+		if _, found := after[fileName]; !found {
+			changed = append(changed, fileName)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// affectedTemplates returns the names of the page templates whose pages have to be rendered again after the input
+// files changed; nil means all pages (the data, a template part, a static file or a config file changed).
+func affectedTemplates(changed []string) []string {
+	if len(changed) == 0 {
+		return nil
+	}
+	names := make([]string, 0)
+	for _, fileName := range changed {
+		dir, file := filepath.Split(filepath.Clean(fileName))
+		if filepath.Clean(dir) != "templates" || filepath.Ext(file) != ".html" {
+			return nil
+		}
+		names = append(names, strings.TrimSuffix(file, ".html"))
+	}
+	return names
+}
+
+// watch rebuilds the site once the inputs changed (and did not change any more for one interval).
+func (s *server) watch() {
+	built := s.fingerprint()
+	current := built
+	pending := false
+	for range time.Tick(s.options.interval) {
+		next := s.fingerprint()
+		if len(changedFiles(current, next)) > 0 {
+			current = next
+			pending = true
+			continue
+		}
+		if !pending {
+			continue
+		}
+		pending = false
+
+		start := time.Now()
+		changed, err := s.build(changedFiles(built, current))
+		built = current
+		if err != nil {
+			log.Printf("rebuild failed: %v", err)
+			continue
+		}
+		log.Printf("rebuilt in %v, %d changed files", time.Since(start).Round(time.Millisecond), len(changed))
+		if len(changed) > 0 {
+			s.notify(changed)
+		}
+	}
+}
+
+func (s *server) notify(changed []string) {
+	s.clientsMutex.Lock()
+	defer s.clientsMutex.Unlock()
+	for client := range s.clients {
+		select {
+		case client <- changed:
+		default:
+			// client is busy; it will catch up with the next change
+		}
+	}
+}
+
+// serveLiveReload streams change notifications to the browser (server-sent events).
+func (s *server) serveLiveReload(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan []string, 1)
+	s.clientsMutex.Lock()
+	s.clients[client] = struct{}{}
+	s.clientsMutex.Unlock()
+	defer func() {
+		s.clientsMutex.Lock()
+		delete(s.clients, client)
+		s.clientsMutex.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, "retry: 1000\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case changed := <-client:
+			buf, _ := json.Marshal(changed)
+			fmt.Fprintf(w, "data: %s\n\n", buf)
+			flusher.Flush()
+		}
+	}
+}
+
+// serveFile serves a file; HTML files get the live reload script injected.
+func (s *server) serveFile(w http.ResponseWriter, r *http.Request, fileName string, status int) {
+	if filepath.Ext(fileName) != ".html" {
+		http.ServeFile(w, r, fileName)
+		return
+	}
+	buf, err := os.ReadFile(fileName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if pos := bytes.LastIndex(buf, []byte("</body>")); pos >= 0 {
+		buf = append(buf[:pos], append([]byte(liveReloadScript), buf[pos:]...)...)
+	} else {
+		buf = append(buf, []byte(liveReloadScript)...)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	w.Write(buf)
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	urlPath := path.Clean("/" + r.URL.Path)
+	if strings.HasSuffix(r.URL.Path, "/") && urlPath != "/" {
+		urlPath += "/"
+	}
+
+	s.mutex.RLock()
+	htaccess := s.htaccess
+	s.mutex.RUnlock()
+
+	if target, ok := htaccess.Match(urlPath); ok {
+		http.Redirect(w, r, target, http.StatusFound)
+		return
+	}
+
+	fileName := s.out.Join(filepath.FromSlash(urlPath))
+	info, err := os.Stat(fileName)
+	if err == nil && info.IsDir() {
+		if !strings.HasSuffix(urlPath, "/") {
+			http.Redirect(w, r, urlPath+"/", http.StatusMovedPermanently)
+			return
+		}
+		fileName = filepath.Join(fileName, "index.html")
+		info, err = os.Stat(fileName)
+	}
+	if err != nil || info.IsDir() {
+		if errorDocument, ok := htaccess.ErrorDocuments[http.StatusNotFound]; ok {
+			s.serveFile(w, r, s.out.Join(filepath.FromSlash(errorDocument)), http.StatusNotFound)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+	s.serveFile(w, r, fileName, http.StatusOK)
+}
+
+func main() {
+	options := parseCommandLine()
+
+	if _, err := os.Stat(options.snapshotFile); options.backupDir == "" && (options.refresh || err != nil) {
+		log.Printf("fetching data snapshot to %s", options.snapshotFile)
+		if err := fetchSnapshot(options); err != nil {
+			log.Fatalf("failed to fetch data snapshot: %v", err)
+		}
+	}
+
+	s := &server{
+		options: options,
+		out:     utils.NewPath(options.outDir),
+		clients: make(map[chan []string]struct{}),
+	}
+	if _, err := s.build(nil); err != nil {
+		log.Fatalf("failed to generate: %v", err)
+	}
+	go s.watch()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(liveReloadPath, s.serveLiveReload)
+	mux.Handle("/", s)
+
+	log.Printf("serving %s on http://%s/", options.outDir, options.addr)
+	if err := http.ListenAndServe(options.addr, mux); err != nil {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"flag"
 	"fmt"
+	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/svengiegerich/heidelberg-run/internal/utils"
 )
@@ -11,7 +13,10 @@ import (
 const (
 	usage = `USAGE: %s [OPTIONS...]
 
-	Update external vendor assets.
+Update external vendor assets: downloads the files pinned in the lock file (verifying TLS and their SHA-256
+checksums) to the vendor dir.
+With -verify, checks the files of the vendor dir against the lock file (offline).
+With -relock, downloads the files and records their checksums in the lock file (after a version bump).
 
 OPTIONS:
 `
@@ -19,10 +24,16 @@ OPTIONS:
 
 type CommandLineOptions struct {
 	vendorDir string
+	lockFile  string
+	verify    bool
+	relock    bool
 }
 
 func parseCommandLine() CommandLineOptions {
 	vendorDir := flag.String("dir", "", "Vendor dir")
+	lockFile := flag.String("lock", "vendor.lock.json", "lock file with versions, URLs and checksums")
+	verify := flag.Bool("verify", false, "only verify the vendored files against the lock file (no downloads)")
+	relock := flag.Bool("relock", false, "download and update the checksums of the lock file")
 
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), usage, os.Args[0])
@@ -30,62 +41,78 @@ func parseCommandLine() CommandLineOptions {
 	}
 	flag.Parse()
 
-	if *vendorDir == "" {
+	if *vendorDir == "" || (*verify && *relock) {
 		flag.Usage()
 		os.Exit(1)
 	}
 
 	return CommandLineOptions{
 		*vendorDir,
+		*lockFile,
+		*verify,
+		*relock,
 	}
 }
 
-func MustDownload(url, targetFile string) {
-	fmt.Printf("Downloading %s to %s\n", url, targetFile)
-	err := utils.Download(url, targetFile)
-	if err != nil {
-		panic(fmt.Sprintf("failed to download %s: %v", url, err))
+func verify(lock utils.VendorLock, vendorDir string) {
+	errs := lock.Verify(vendorDir)
+	for _, err := range errs {
+		fmt.Printf("FAILED: %v\n", err)
+	}
+	if len(errs) > 0 {
+		log.Fatalf("%d vendored files do not match the lock file", len(errs))
+	}
+	fmt.Println("All vendored files match the lock file")
+}
+
+func update(lock utils.VendorLock, vendorDir string, relock bool) bool {
+	changed := false
+	for p := range lock.Packages {
+		pkg := &lock.Packages[p]
+		for f := range pkg.Files {
+			file := &pkg.Files[f]
+			url := pkg.Url(*file)
+			target := filepath.Join(vendorDir, filepath.FromSlash(file.Target))
+			fmt.Printf("Downloading %s to %s\n", url, target)
+
+			expected := file.Sha256
+			if relock {
+				expected = ""
+			} else if expected == "" {
+				log.Fatalf("no checksum for %s in lock file; run with -relock", file.Target)
+			}
+			sum, err := utils.DownloadVerified(url, target, expected)
+			if err != nil {
+				log.Fatalf("failed to download %s %s: %v", pkg.Package, pkg.Version, err)
+			}
+			if sum != file.Sha256 || url != file.Resolved {
+				fmt.Printf("  new checksum %s\n", sum)
+				file.Sha256 = sum
+				file.Resolved = url
+				changed = true
+			}
+		}
 	}
+	return changed
 }
 
 func main() {
 	options := parseCommandLine()
 
-	// renovate: datasource=npm depName=bulma
-	bulmaVersion := "1.0.4"
-	// renovate: datasource=npm depName=leaflet
-	leafletVersion := "1.9.4"
-	// renovate: datasource=npm depName=leaflet-gesture-handling
-	leafletGestureHandlingVersion := "1.2.2"
-
-	leafletLegendVersion := "v1.0.0"
-
-	// URLs
-	bulmaUrl := utils.Url(fmt.Sprintf("https://cdnjs.cloudflare.com/ajax/libs/bulma/%s", bulmaVersion))
-	leafletUrl := utils.Url(fmt.Sprintf("https://cdnjs.cloudflare.com/ajax/libs/leaflet/%s", leafletVersion))
-	leafletGestureHandlingUrl := utils.Url(fmt.Sprintf("https://raw.githubusercontent.com/elmarquis/Leaflet.GestureHandling/refs/tags/v%s", leafletGestureHandlingVersion))
-	leafletLegendUrl := utils.Url(fmt.Sprintf("https://raw.githubusercontent.com/ptma/Leaflet.Legend/%s", leafletLegendVersion))
-
-	vendorDir := utils.Path(options.vendorDir)
-
-	// download bulma
-	MustDownload(bulmaUrl.Join("css/bulma.min.css"), vendorDir.Join("bulma", "bulma.css"))
-
-	// download leaflet
-	MustDownload(leafletUrl.Join("leaflet.min.css"), vendorDir.Join("leaflet", "leaflet.css"))
-	MustDownload(leafletUrl.Join("leaflet.min.js"), vendorDir.Join("leaflet", "leaflet.js"))
-	MustDownload(leafletUrl.Join("images/marker-icon.png"), vendorDir.Join("leaflet", "marker-icon.png"))
-	MustDownload(leafletUrl.Join("images/marker-icon-2x.png"), vendorDir.Join("leaflet", "marker-icon-2x.png"))
-	MustDownload(leafletUrl.Join("images/marker-shadow.png"), vendorDir.Join("leaflet", "marker-shadow.png"))
-
-	// download leaflet-gesture-handling
-	MustDownload(leafletGestureHandlingUrl.Join("dist/leaflet-gesture-handling.min.js"), vendorDir.Join("leaflet-gesture-handling", "leaflet-gesture-handling.js"))
-	MustDownload(leafletGestureHandlingUrl.Join("dist/leaflet-gesture-handling.min.css"), vendorDir.Join("leaflet-gesture-handling", "leaflet-gesture-handling.css"))
+	lock, err := utils.LoadVendorLock(options.lockFile)
+	if err != nil {
+		log.Fatalf("failed to load lock file: %v", err)
+	}
 
-	// download leaflet-legend
-	MustDownload(leafletLegendUrl.Join("src/leaflet.legend.css"), vendorDir.Join("leaflet-legend", "leaflet-legend.css"))
-	MustDownload(leafletLegendUrl.Join("src/leaflet.legend.js"), vendorDir.Join("leaflet-legend", "leaflet-legend.js"))
+	if options.verify {
+		verify(lock, options.vendorDir)
+		return
+	}
 
-	// download umami
-	MustDownload("https://cloud.umami.is/script.js", vendorDir.Join("umami", "umami.js"))
+	if update(lock, options.vendorDir, options.relock) {
+		if err := lock.Save(options.lockFile); err != nil {
+			log.Fatalf("failed to save lock file: %v", err)
+		}
+		fmt.Printf("Updated checksums in %s\n", options.lockFile)
+	}
 }
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+const (
+	Version     = 1
+	License     = "CC BY 4.0"
+	LicenseUrl  = "https://creativecommons.org/licenses/by/4.0/"
+	Attribution = "heidelberg.run"
+	dateFormat  = "2006-01-02"
+)
+
+type Link struct {
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type Location struct {
+	Name      string   `json:"name,omitempty"`
+	City      string   `json:"city,omitempty"`
+	Country   string   `json:"country,omitempty"`
+	Lat       *float64 `json:"lat,omitempty"`
+	Lon       *float64 `json:"lon,omitempty"`
+	Distance  string   `json:"distance,omitempty"`
+	Direction string   `json:"direction,omitempty"`
+}
+
+type Dates struct {
+	Label string `json:"label,omitempty"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+type Event struct {
+	Slug     string   `json:"slug"`
+	Url      string   `json:"url"`
+	Type     string   `json:"type"`
+	Name     string   `json:"name"`
+	Dates    *Dates   `json:"dates,omitempty"`
+	Status   string   `json:"status"`
+	Note     string   `json:"note,omitempty"`
+	Location Location `json:"location"`
+	Website  *Link    `json:"website,omitempty"`
+	Links    []Link   `json:"links"`
+	Tags     []string `json:"tags"`
+	Series   []string `json:"series"`
+	Details  string   `json:"details,omitempty"`
+	Calendar string   `json:"calendar,omitempty"`
+}
+
+type Tag struct {
+	Slug        string `json:"slug"`
+	Url         string `json:"url"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Serie struct {
+	Slug        string `json:"slug"`
+	Url         string `json:"url"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Links       []Link `json:"links"`
+}
+
+// Envelope is the common top-level object of all API files.
+type Envelope struct {
+	Version     int    `json:"version"`
+	Generated   string `json:"generated"`
+	License     string `json:"license"`
+	LicenseUrl  string `json:"license_url"`
+	Attribution string `json:"attribution"`
+	Schema      string `json:"$schema,omitempty"`
+}
+
+type EventList struct {
+	Envelope
+	Events []Event `json:"events"`
+}
+
+type TagList struct {
+	Envelope
+	Tags []Tag `json:"tags"`
+}
+
+type SerieList struct {
+	Envelope
+	Series []Serie `json:"series"`
+}
+
+type TagDetails struct {
+	Envelope
+	Tag       Tag     `json:"tag"`
+	Events    []Event `json:"events"`
+	EventsOld []Event `json:"events_old"`
+	Groups    []Event `json:"groups"`
+	Shops     []Event `json:"shops"`
+}
+
+type SerieDetails struct {
+	Envelope
+	Serie     Serie   `json:"serie"`
+	Events    []Event `json:"events"`
+	EventsOld []Event `json:"events_old"`
+	Groups    []Event `json:"groups"`
+	Shops     []Event `json:"shops"`
+}
+
+func status(event *events.Event) string {
+	switch {
+	case event.Cancelled:
+		return "cancelled"
+	case event.Old:
+		return "past"
+	case event.Special:
+		return "special"
+	default:
+		return "active"
+	}
+}
+
+func convertLinks(links []*utils.Link) []Link {
+	res := make([]Link, 0, len(links))
+	for _, link := range links {
+		res = append(res, Link{link.Name, link.Url})
+	}
+	return res
+}
+
+func ConvertEvent(event *events.Event, baseUrl utils.Url) Event {
+	e := Event{
+		Slug:   event.Slug(),
+		Url:    baseUrl.Join(event.Slug()),
+		Type:   event.Type,
+		Name:   event.Name.Orig,
+		Status: status(event),
+		Note:   event.Status,
+		Location: Location{
+			Name:      event.Location.NameNoFlag(),
+			City:      event.Location.City,
+			Country:   event.Location.Country,
+			Distance:  event.Location.Distance,
+			Direction: event.Location.Direction,
+		},
+		Links:    convertLinks(event.Links),
+		Tags:     make([]string, 0, len(event.Tags)),
+		Series:   make([]string, 0, len(event.Series)),
+		Details:  string(event.Details),
+		Calendar: event.Calendar,
+	}
+	if event.Time.Original != "" {
+		e.Dates = &Dates{Label: event.Time.Formatted}
+		if !event.Time.IsZero() {
+			e.Dates.From = event.Time.From.Format(dateFormat)
+			e.Dates.To = event.Time.To.Format(dateFormat)
+		}
+	}
+	if event.Location.HasGeo() {
+		lat, lon := event.Location.Lat, event.Location.Lon
+		e.Location.Lat = &lat
+		e.Location.Lon = &lon
+	}
+	if event.MainLink != nil && event.MainLink.Url != "" {
+		e.Website = &Link{event.MainLink.Name, event.MainLink.Url}
+	}
+	for _, tag := range event.Tags {
+		e.Tags = append(e.Tags, tag.Name.Sanitized)
+	}
+	for _, serie := range event.Series {
+		e.Series = append(e.Series, serie.Name.Sanitized)
+	}
+	return e
+}
+
+func ConvertEvents(eventList []*events.Event, baseUrl utils.Url) []Event {
+	res := make([]Event, 0, len(eventList))
+	for _, event := range eventList {
+		if event.IsSeparator() {
+			continue
+		}
+		res = append(res, ConvertEvent(event, baseUrl))
+	}
+	return res
+}
+
+func ConvertTag(tag *events.Tag, baseUrl utils.Url) Tag {
+	return Tag{tag.Name.Sanitized, baseUrl.Join(tag.Slug()), tag.Name.Orig, tag.Description}
+}
+
+func ConvertSerie(serie *events.Serie, baseUrl utils.Url) Serie {
+	return Serie{serie.Name.Sanitized, baseUrl.Join(serie.Slug()), serie.Name.Orig, string(serie.Description), convertLinks(serie.Links)}
+}
+
+// Writer emits the versioned JSON files of the static API below outDir.
+type Writer struct {
+	out      utils.Path
+	baseUrl  utils.Url
+	envelope Envelope
+}
+
+func NewWriter(out utils.Path, baseUrl utils.Url, now time.Time) Writer {
+	return Writer{
+		out:     out,
+		baseUrl: baseUrl,
+		envelope: Envelope{
+			Version:     Version,
+			Generated:   now.Format(time.RFC3339),
+			License:     License,
+			LicenseUrl:  LicenseUrl,
+			Attribution: Attribution,
+			Schema:      baseUrl.Join(fmt.Sprintf("api/v%d/schema.json", Version)),
+		},
+	}
+}
+
+// Envelope returns the common top-level object, e.g. for JSON files outside of the API directory.
+func (w Writer) Envelope() Envelope {
+	return w.envelope
+}
+
+func (w Writer) path(parts ...string) string {
+	return w.out.Join(append([]string{"api", fmt.Sprintf("v%d", Version)}, parts...)...)
+}
+
+func (w Writer) writeEvents(fileName string, eventList []*events.Event) error {
+	return utils.WriteJSON(w.path(fileName), EventList{w.envelope, ConvertEvents(eventList, w.baseUrl)})
+}
+
+func (w Writer) Write(data events.Data) error {
+	if err := w.writeEvents("events.json", data.Events); err != nil {
+		return err
+	}
+	if err := w.writeEvents("events-old.json", data.EventsOld); err != nil {
+		return err
+	}
+	if err := w.writeEvents("groups.json", data.Groups); err != nil {
+		return err
+	}
+	if err := w.writeEvents("shops.json", data.Shops); err != nil {
+		return err
+	}
+
+	tags := make([]Tag, 0, len(data.Tags))
+	for _, tag := range data.Tags {
+		t := ConvertTag(tag, w.baseUrl)
+		tags = append(tags, t)
+		details := TagDetails{
+			w.envelope,
+			t,
+			ConvertEvents(tag.Events, w.baseUrl),
+			ConvertEvents(tag.EventsOld, w.baseUrl),
+			ConvertEvents(tag.Groups, w.baseUrl),
+			ConvertEvents(tag.Shops, w.baseUrl),
+		}
+		if err := utils.WriteJSON(w.path("tag", t.Slug+".json"), details); err != nil {
+			return err
+		}
+	}
+	if err := utils.WriteJSON(w.path("tags.json"), TagList{w.envelope, tags}); err != nil {
+		return err
+	}
+
+	series := make([]Serie, 0, len(data.Series)+len(data.SeriesOld))
+	for _, serieList := range [][]*events.Serie{data.Series, data.SeriesOld} {
+		for _, serie := range serieList {
+			s := ConvertSerie(serie, w.baseUrl)
+			series = append(series, s)
+			details := SerieDetails{
+				w.envelope,
+				s,
+				ConvertEvents(serie.Events, w.baseUrl),
+				ConvertEvents(serie.EventsOld, w.baseUrl),
+				ConvertEvents(serie.Groups, w.baseUrl),
+				ConvertEvents(serie.Shops, w.baseUrl),
+			}
+			if err := utils.WriteJSON(w.path("serie", s.Slug+".json"), details); err != nil {
+				return err
+			}
+		}
+	}
+	if err := utils.WriteJSON(w.path("series.json"), SerieList{w.envelope, series}); err != nil {
+		return err
+	}
+
+	return nil
+}
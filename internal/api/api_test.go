@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestConvertEvent(t *testing.T) {
+	timeRange, err := utils.CreateTimeRange("12.07.2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := &events.Event{
+		Type:      "event",
+		Name:      utils.NewName("Test Lauf"),
+		Time:      timeRange,
+		Cancelled: true,
+		Location:  events.CreateLocation("Heidelberg", "49.4,8.7"),
+		MainLink:  utils.CreateUnnamedLink("https://example.com/"),
+		Links:     []*utils.Link{utils.CreateLink("Anmeldung", "https://example.com/reg")},
+		Tags:      []*events.Tag{events.CreateTag("traillauf")},
+	}
+
+	e := ConvertEvent(event, utils.Url("https://heidelberg.run"))
+	if e.Slug != "event/2025-test-lauf.html" {
+		t.Errorf("Slug = %q", e.Slug)
+	}
+	if e.Url != "https://heidelberg.run/event/2025-test-lauf.html" {
+		t.Errorf("Url = %q", e.Url)
+	}
+	if e.Status != "cancelled" {
+		t.Errorf("Status = %q; want cancelled", e.Status)
+	}
+	if e.Dates == nil || e.Dates.From != "2025-07-12" || e.Dates.To != "2025-07-12" {
+		t.Errorf("Dates = %v", e.Dates)
+	}
+	if e.Location.Lat == nil || *e.Location.Lat != 49.4 || e.Location.Lon == nil || *e.Location.Lon != 8.7 {
+		t.Errorf("Location = %v", e.Location)
+	}
+	if len(e.Tags) != 1 || e.Tags[0] != "traillauf" {
+		t.Errorf("Tags = %v", e.Tags)
+	}
+	if len(e.Links) != 1 || e.Links[0].Name != "Anmeldung" {
+		t.Errorf("Links = %v", e.Links)
+	}
+	if e.Website == nil || e.Website.Name != "example.com" {
+		t.Errorf("Website = %v", e.Website)
+	}
+}
+
+func TestConvertFeatures(t *testing.T) {
+	withGeo := &events.Event{Type: "group", Name: utils.NewName("Lauftreff"), Location: events.CreateLocation("Heidelberg", "49.4,8.7")}
+	withoutGeo := &events.Event{Type: "group", Name: utils.NewName("Ohne Ort"), Location: events.CreateLocation("Heidelberg", "")}
+
+	features := ConvertFeatures([]*events.Event{withGeo, withoutGeo}, "groups")
+	if len(features) != 1 {
+		t.Fatalf("len(features) = %d; want 1", len(features))
+	}
+	f := features[0]
+	if f.Geometry.Coordinates != [2]float64{8.7, 49.4} {
+		t.Errorf("Coordinates = %v; want [lon, lat]", f.Geometry.Coordinates)
+	}
+	if f.Properties.Layer != "groups" || f.Properties.NiceType != "Lauftreff" || f.Properties.Slug != "group/lauftreff.html" {
+		t.Errorf("Properties = %+v", f.Properties)
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+type Geometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type FeatureProperties struct {
+	Layer    string   `json:"layer"`
+	Type     string   `json:"type"`
+	NiceType string   `json:"nicetype"`
+	Status   string   `json:"status"`
+	Name     string   `json:"name"`
+	Slug     string   `json:"slug"`
+	Date     string   `json:"date,omitempty"`
+	From     string   `json:"from,omitempty"`
+	To       string   `json:"to,omitempty"`
+	Location string   `json:"location,omitempty"`
+	Tags     []string `json:"tags"`
+}
+
+type Feature struct {
+	Type       string            `json:"type"`
+	Geometry   Geometry          `json:"geometry"`
+	Properties FeatureProperties `json:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// MapLayer is a group of entries that is shown as a separate layer on the map.
+type MapLayer struct {
+	Name   string
+	Events []*events.Event
+}
+
+func (l MapLayer) FileName() string {
+	return "map-" + l.Name + ".geojson"
+}
+
+func MapLayers(data events.Data) []MapLayer {
+	return []MapLayer{
+		{"events", data.Events},
+		{"events-old", data.EventsOld},
+		{"groups", data.Groups},
+		{"shops", data.Shops},
+	}
+}
+
+func ConvertFeature(event *events.Event, layer string) Feature {
+	e := ConvertEvent(event, "")
+	f := Feature{
+		Type: "Feature",
+		Geometry: Geometry{
+			Type:        "Point",
+			Coordinates: [2]float64{event.Location.Lon, event.Location.Lat},
+		},
+		Properties: FeatureProperties{
+			Layer:    layer,
+			Type:     event.Type,
+			NiceType: event.NiceType(),
+			Status:   e.Status,
+			Name:     event.Name.Orig,
+			Slug:     e.Slug,
+			Location: event.Location.Name(),
+			Tags:     e.Tags,
+		},
+	}
+	if e.Dates != nil {
+		f.Properties.Date = e.Dates.Label
+		f.Properties.From = e.Dates.From
+		f.Properties.To = e.Dates.To
+	}
+	return f
+}
+
+func ConvertFeatures(eventList []*events.Event, layer string) []Feature {
+	features := make([]Feature, 0, len(eventList))
+	for _, event := range eventList {
+		if event.IsSeparator() || !event.Location.HasGeo() {
+			continue
+		}
+		features = append(features, ConvertFeature(event, layer))
+	}
+	return features
+}
+
+// WriteGeoJSON creates "map.geojson" containing all entries with coordinates plus one file per map layer.
+func WriteGeoJSON(out utils.Path, data events.Data) error {
+	all := FeatureCollection{"FeatureCollection", make([]Feature, 0)}
+	for _, layer := range MapLayers(data) {
+		features := ConvertFeatures(layer.Events, layer.Name)
+		all.Features = append(all.Features, features...)
+		if err := utils.WriteJSON(out.Join(layer.FileName()), FeatureCollection{"FeatureCollection", features}); err != nil {
+			return err
+		}
+	}
+	return utils.WriteJSON(out.Join("map.geojson"), all)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// schemaValidator checks a JSON value against the subset of JSON Schema used by static/api/v1/schema.json: $ref
+// (to $defs), allOf, anyOf, oneOf, type, const, enum, required, properties and items.
+type schemaValidator struct {
+	defs map[string]interface{}
+}
+
+func (v schemaValidator) validate(schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, found := v.defs[strings.TrimPrefix(ref, "#/$defs/")]
+		if !found {
+			return fmt.Errorf("%s: unknown $ref %s", path, ref)
+		}
+		if err := v.validate(def.(map[string]interface{}), value, path); err != nil {
+			return err
+		}
+	}
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if err := v.validate(sub.(map[string]interface{}), value, path); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		subs, ok := schema[keyword].([]interface{})
+		if !ok {
+			continue
+		}
+		matches := 0
+		for _, sub := range subs {
+			if v.validate(sub.(map[string]interface{}), value, path) == nil {
+				matches += 1
+			}
+		}
+		if matches == 0 || (keyword == "oneOf" && matches > 1) {
+			return fmt.Errorf("%s: %d of the %s branches match", path, matches, keyword)
+		}
+	}
+	if c, ok := schema["const"]; ok && !reflect.DeepEqual(c, value) {
+		return fmt.Errorf("%s: expected %v, got %v", path, c, value)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || reflect.DeepEqual(e, value)
+		}
+		if !found {
+			return fmt.Errorf("%s: %v not in %v", path, value, enum)
+		}
+	}
+	if t, ok := schema["type"].(string); ok {
+		valid := false
+		switch t {
+		case "object":
+			_, valid = value.(map[string]interface{})
+		case "array":
+			_, valid = value.([]interface{})
+		case "string":
+			_, valid = value.(string)
+		case "number":
+			_, valid = value.(float64)
+		}
+		if !valid {
+			return fmt.Errorf("%s: expected %s, got %T", path, t, value)
+		}
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, found := object[name.(string)]; !found {
+					return fmt.Errorf("%s: missing %s", path, name)
+				}
+			}
+		}
+		if properties, ok := schema["properties"].(map[string]interface{}); ok {
+			for name, sub := range properties {
+				if property, found := object[name]; found {
+					if err := v.validate(sub.(map[string]interface{}), property, path+"."+name); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	if array, ok := value.([]interface{}); ok {
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range array {
+				if err := v.validate(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func readJSON(t *testing.T, fileName string) interface{} {
+	buf, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(buf, &value); err != nil {
+		t.Fatalf("%s: %v", fileName, err)
+	}
+	return value
+}
+
+func TestSchema(t *testing.T) {
+	schema := readJSON(t, filepath.Join("..", "..", "static", "api", "v1", "schema.json")).(map[string]interface{})
+	v := schemaValidator{schema["$defs"].(map[string]interface{})}
+
+	timeRange, err := utils.CreateTimeRange("12.07.2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := events.CreateTag("traillauf")
+	tag.Description = "Läufe abseits befestigter Wege"
+	serie := events.CreateSerie("cup", "Odenwald Cup")
+	serie.Links = append(serie.Links, utils.CreateLink("Wertung", "https://example.com/cup"))
+	event := &events.Event{
+		Type:     "event",
+		Name:     utils.NewName("Test Lauf"),
+		Time:     timeRange,
+		Location: events.CreateLocation("Heidelberg", "49.4,8.7"),
+		MainLink: utils.CreateUnnamedLink("https://example.com/"),
+		Links:    []*utils.Link{utils.CreateLink("Anmeldung", "https://example.com/reg")},
+		Tags:     []*events.Tag{tag},
+		Series:   []*events.Serie{serie},
+	}
+	group := &events.Event{Type: "group", Name: utils.NewName("Lauftreff"), Location: events.CreateLocation("Heidelberg", "")}
+	tag.Events = append(tag.Events, event)
+	serie.Events = append(serie.Events, event)
+	serie.Groups = append(serie.Groups, group)
+	data := events.Data{
+		Events: []*events.Event{event},
+		Groups: []*events.Event{group},
+		Tags:   []*events.Tag{tag},
+		Series: []*events.Serie{serie},
+	}
+
+	out := t.TempDir()
+	if err := NewWriter(utils.NewPath(out), utils.Url("https://heidelberg.run"), time.Now()).Write(data); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"events.json", "events-old.json", "groups.json", "shops.json", "tags.json", "series.json", "tag/traillauf.json", "serie/cup.json"} {
+		fileName := filepath.Join(out, "api", "v1", filepath.FromSlash(file))
+		if err := v.validate(schema, readJSON(t, fileName), file); err != nil {
+			t.Errorf("%s does not match the schema: %v", file, err)
+		}
+	}
+}
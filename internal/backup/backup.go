@@ -0,0 +1,354 @@
+// Package backup writes backups of the events spreadsheet (ODS export, CSV per sheet, normalised JSON), verifies them
+// by parsing them back and prunes old backups.
+package backup
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/api"
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+const (
+	DateFormat = "2006-01-02"
+	OdsFile    = "sheet.ods"
+	CsvDir     = "csv"
+	JsonFile   = "data.json"
+	indexFile  = "index.csv" // sheet names (in spreadsheet order) and their CSV files
+	odsMime    = "application/vnd.oasis.opendocument.spreadsheet"
+)
+
+// Normalized is the parsed content of the spreadsheet (as of Created) in the format of the JSON API.
+type Normalized struct {
+	Created    string      `json:"created"`
+	Events     []api.Event `json:"events"`      // upcoming events
+	PastEvents []api.Event `json:"past_events"` // most recent first
+	Groups     []api.Event `json:"groups"`
+	Shops      []api.Event `json:"shops"`
+	Tags       []api.Tag   `json:"tags"`
+	Series     []api.Serie `json:"series"`
+}
+
+func Normalize(data events.Data, baseUrl utils.Url, today time.Time) Normalized {
+	n := Normalized{
+		Created:    today.Format(DateFormat),
+		Events:     api.ConvertEvents(data.Events, baseUrl),
+		PastEvents: api.ConvertEvents(data.EventsOld, baseUrl),
+		Groups:     api.ConvertEvents(data.Groups, baseUrl),
+		Shops:      api.ConvertEvents(data.Shops, baseUrl),
+		Tags:       make([]api.Tag, 0, len(data.Tags)),
+		Series:     make([]api.Serie, 0, len(data.Series)+len(data.SeriesOld)),
+	}
+	for _, tag := range data.Tags {
+		n.Tags = append(n.Tags, api.ConvertTag(tag, baseUrl))
+	}
+	for _, serie := range append(append([]*events.Serie{}, data.Series...), data.SeriesOld...) {
+		n.Series = append(n.Series, api.ConvertSerie(serie, baseUrl))
+	}
+	return n
+}
+
+// Summary counts the entries of a backup.
+type Summary struct {
+	Sheets     int
+	Events     int
+	PastEvents int
+	Groups     int
+	Shops      int
+	Tags       int
+	Series     int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d sheets, %d upcoming events, %d past events, %d groups, %d shops, %d tags, %d series",
+		s.Sheets, s.Events, s.PastEvents, s.Groups, s.Shops, s.Tags, s.Series)
+}
+
+func (n Normalized) summary() Summary {
+	return Summary{0, len(n.Events), len(n.PastEvents), len(n.Groups), len(n.Shops), len(n.Tags), len(n.Series)}
+}
+
+// trimRow drops trailing empty cells (Google Sheets omits them, too).
+func trimRow(row []string) []string {
+	for len(row) > 0 && row[len(row)-1] == "" {
+		row = row[:len(row)-1]
+	}
+	return row
+}
+
+// csvFileName returns a file name for a sheet name.
+func csvFileName(index int, sheet string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>| `, r) {
+			return '_'
+		}
+		return r
+	}, sheet)
+	return fmt.Sprintf("%02d-%s.csv", index+1, name)
+}
+
+func writeCsvFile(fileName string, rows [][]string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	for _, row := range rows {
+		row = trimRow(row)
+		if len(row) == 0 {
+			// the csv package writes empty records as blank lines, which it skips when reading
+			w.Flush()
+			if _, err := f.WriteString("\"\"\n"); err != nil {
+				f.Close()
+				return err
+			}
+			continue
+		}
+		if err := w.Write(row); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readCsvFile(fileName string) ([][]string, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		rows[i] = trimRow(row)
+	}
+	return rows, nil
+}
+
+// WriteCsv writes one CSV file per sheet of snapshot to dir.
+func WriteCsv(dir string, snapshot *events.Snapshot) error {
+	if err := os.MkdirAll(dir, 0770); err != nil {
+		return fmt.Errorf("create csv dir: %w", err)
+	}
+	index := [][]string{{"SHEET", "FILE"}}
+	for i, sheet := range snapshot.Sheets {
+		fileName := csvFileName(i, sheet)
+		if err := writeCsvFile(filepath.Join(dir, fileName), snapshot.Tables[sheet]); err != nil {
+			return fmt.Errorf("write csv of sheet '%s': %w", sheet, err)
+		}
+		index = append(index, []string{sheet, fileName})
+	}
+	if err := writeCsvFile(filepath.Join(dir, indexFile), index); err != nil {
+		return fmt.Errorf("write csv index: %w", err)
+	}
+	return nil
+}
+
+// LoadCsv reads the CSV files of dir (written by WriteCsv) as a snapshot.
+func LoadCsv(dir string) (*events.Snapshot, error) {
+	index, err := readCsvFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		return nil, fmt.Errorf("read csv index: %w", err)
+	}
+	snapshot := &events.Snapshot{Sheets: make([]string, 0, len(index)), Tables: make(map[string][][]string)}
+	for line, entry := range index {
+		if line == 0 {
+			continue
+		}
+		if len(entry) != 2 {
+			return nil, fmt.Errorf("csv index: bad entry in line %d", line+1)
+		}
+		sheet, fileName := entry[0], entry[1]
+		if filepath.Base(fileName) != fileName {
+			return nil, fmt.Errorf("csv index: bad file name '%s'", fileName)
+		}
+		rows, err := readCsvFile(filepath.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("read csv of sheet '%s': %w", sheet, err)
+		}
+		snapshot.Sheets = append(snapshot.Sheets, sheet)
+		snapshot.Tables[sheet] = rows
+	}
+	return snapshot, nil
+}
+
+// Load reads the snapshot of a backup directory (from its CSV files).
+func Load(dir string) (*events.Snapshot, error) {
+	return LoadCsv(filepath.Join(dir, CsvDir))
+}
+
+// LoadSource reads the snapshot of a backup directory (its CSV files, or sheet.ods if there are none), of an ODS
+// export (e.g. a legacy backup 'YYYY-MM-DD.ods') or of a data snapshot file (*.json).
+func LoadSource(source string) (*events.Snapshot, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case info.IsDir():
+		if _, err := os.Stat(filepath.Join(source, CsvDir)); err == nil {
+			return Load(source)
+		}
+		return LoadOds(filepath.Join(source, OdsFile))
+	case strings.HasSuffix(source, ".ods"):
+		return LoadOds(source)
+	case strings.HasSuffix(source, ".json"):
+		return events.LoadSnapshot(source)
+	}
+	return nil, fmt.Errorf("unknown source '%s'", source)
+}
+
+// SourceDate returns the day a backup was taken, from the name of its directory 'YYYY-MM-DD' or of a legacy backup
+// file 'YYYY-MM-DD.ods'.
+func SourceDate(source string) (time.Time, error) {
+	name := strings.TrimSuffix(filepath.Base(filepath.Clean(source)), ".ods")
+	date, err := time.ParseInLocation(DateFormat, name, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("backup '%s' is not named YYYY-MM-DD", source)
+	}
+	return date, nil
+}
+
+// WriteJson writes the normalised data as JSON.
+func WriteJson(fileName string, normalized Normalized) error {
+	return utils.WriteJSON(fileName, normalized)
+}
+
+func loadJson(fileName string) (Normalized, error) {
+	buf, err := os.ReadFile(fileName)
+	if err != nil {
+		return Normalized{}, err
+	}
+	var normalized Normalized
+	if err := json.Unmarshal(buf, &normalized); err != nil {
+		return Normalized{}, err
+	}
+	return normalized, nil
+}
+
+// verifyOds checks that fileName is an OpenDocument spreadsheet (a zip archive with the ODS mimetype and content).
+func verifyOds(fileName string) error {
+	r, err := zip.OpenReader(fileName)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	found := make(map[string]*zip.File)
+	for _, f := range r.File {
+		found[f.Name] = f
+	}
+	if found["content.xml"] == nil {
+		return fmt.Errorf("missing content.xml")
+	}
+	mimetype := found["mimetype"]
+	if mimetype == nil {
+		return fmt.Errorf("missing mimetype")
+	}
+	rc, err := mimetype.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	buf, err := io.ReadAll(rc)
+	if err != nil {
+		return err
+	}
+	if string(buf) != odsMime {
+		return fmt.Errorf("unexpected mimetype '%s'", buf)
+	}
+	return nil
+}
+
+func trimTables(snapshot *events.Snapshot) map[string][][]string {
+	tables := make(map[string][][]string)
+	for _, sheet := range snapshot.Sheets {
+		rows := make([][]string, 0, len(snapshot.Tables[sheet]))
+		for _, row := range snapshot.Tables[sheet] {
+			rows = append(rows, trimRow(row))
+		}
+		tables[sheet] = rows
+	}
+	return tables
+}
+
+// Verify parses the files of a backup directory back: the ODS must be a valid spreadsheet, the CSV files must parse
+// like the spreadsheet (and equal expected, if given), and the JSON must match the data parsed from the CSV files.
+// today is the day the backup was taken.
+func Verify(dir string, expected *events.Snapshot, today time.Time) (Summary, error) {
+	var summary Summary
+	found := false
+
+	odsFile := filepath.Join(dir, OdsFile)
+	if _, err := os.Stat(odsFile); err == nil {
+		found = true
+		if err := verifyOds(odsFile); err != nil {
+			return Summary{}, fmt.Errorf("verify %s: %w", odsFile, err)
+		}
+	}
+
+	var csvSummary *Summary
+	if _, err := os.Stat(filepath.Join(dir, CsvDir)); err == nil {
+		found = true
+		snapshot, err := Load(dir)
+		if err != nil {
+			return Summary{}, fmt.Errorf("verify csv: %w", err)
+		}
+		if expected != nil {
+			if !reflect.DeepEqual(snapshot.Sheets, expected.Sheets) || !reflect.DeepEqual(trimTables(snapshot), trimTables(expected)) {
+				return Summary{}, fmt.Errorf("verify csv: tables differ from the spreadsheet")
+			}
+		}
+		data, err := events.FetchDataFrom(snapshot, today, nil) // like the backup's JSON: the sheet's tags only
+		if err != nil {
+			return Summary{}, fmt.Errorf("verify csv: parse tables: %w", err)
+		}
+		s := Normalize(data, "", today).summary()
+		s.Sheets = len(snapshot.Sheets)
+		csvSummary = &s
+		summary = s
+	}
+
+	jsonFile := filepath.Join(dir, JsonFile)
+	if _, err := os.Stat(jsonFile); err == nil {
+		found = true
+		normalized, err := loadJson(jsonFile)
+		if err != nil {
+			return Summary{}, fmt.Errorf("verify %s: %w", jsonFile, err)
+		}
+		s := normalized.summary()
+		if csvSummary != nil {
+			s.Sheets = csvSummary.Sheets
+			if s != *csvSummary {
+				return Summary{}, fmt.Errorf("verify %s: entries (%v) differ from csv (%v)", jsonFile, s, *csvSummary)
+			}
+		}
+		if csvSummary == nil {
+			summary = s
+		}
+	}
+
+	if !found {
+		return Summary{}, fmt.Errorf("verify %s: no backup files found", dir)
+	}
+	return summary, nil
+}
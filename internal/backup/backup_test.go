@@ -0,0 +1,208 @@
+package backup
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+)
+
+func testSnapshot() *events.Snapshot {
+	eventColumns := []string{"DATE", "NAME", "NAME2", "SEO", "STATUS", "URL", "DESCRIPTION", "LOCATION", "COORDINATES", "REGISTRATION", "TAGS", "LINK1"}
+	return &events.Snapshot{
+		Sheets: []string{"Events2025", "Events2026", "Groups", "Shops", "Parkrun", "Tags", "Series"},
+		Tables: map[string][][]string{
+			"Events2025": {
+				eventColumns,
+				{"10.05.2025", "Maienlauf", "", "", "", "https://example.com/mai", "Ein Lauf im Mai, \"mit\" Zeilen-\numbruch", "Heidelberg", "49.41,8.69", "", "Volkslauf", ""},
+			},
+			"Events2026": {
+				eventColumns,
+				{},
+				{"17.05.2026", "Odenwald Trail", "", "", "", "https://example.com/trail", "Trail im Odenwald", "Wald-Michelbach", "49.57,8.83", "", "Traillauf", ""},
+			},
+			"Groups":  {eventColumns},
+			"Shops":   {eventColumns},
+			"Parkrun": {{"DATE", "INDEX", "RUNNERS", "TEMP", "SPECIAL", "CAFE", "RESULTS", "REPORT", "AUTHOR", "PHOTOS"}},
+			"Tags":    {{"TAG", "NAME", "DESCRIPTION"}},
+			"Series":  {{"NAME", "DESCRIPTION", "LINK1"}},
+		},
+	}
+}
+
+func TestBackup(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "2026-01-01")
+	today := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := testSnapshot()
+
+	if err := WriteCsv(filepath.Join(dir, CsvDir), snapshot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Sheets, snapshot.Sheets) || !reflect.DeepEqual(trimTables(loaded), trimTables(snapshot)) {
+		t.Errorf("csv round trip changed the tables:\n%v\n%v", loaded.Tables, snapshot.Tables)
+	}
+
+	data, err := events.FetchDataFrom(snapshot, today, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteJson(filepath.Join(dir, JsonFile), Normalize(data, "https://example.com", today)); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := Verify(dir, snapshot, today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Summary{7, 1, 1, 0, 0, 2, 0}); summary != expected {
+		t.Errorf("expected %v, got %v", expected, summary)
+	}
+
+	// a modified table fails the verification
+	snapshot.Tables["Groups"] = append(snapshot.Tables["Groups"], []string{"", "Lauftreff"})
+	if _, err := Verify(dir, snapshot, today); err == nil {
+		t.Errorf("expected error for differing tables")
+	}
+
+	// a broken ODS file, too
+	if err := os.WriteFile(filepath.Join(dir, OdsFile), []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(dir, nil, today); err == nil {
+		t.Errorf("expected error for broken ods")
+	}
+}
+
+func TestExpired(t *testing.T) {
+	day := func(s string) Entry {
+		date, err := time.Parse(DateFormat, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Entry{s, date}
+	}
+	entries := []Entry{
+		day("2025-11-03"), day("2025-11-20"),
+		day("2025-12-01"), day("2025-12-15"), day("2025-12-31"),
+		day("2026-01-01"), day("2026-01-02"), day("2026-01-20"),
+	}
+	now := time.Date(2026, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+	expired := make([]string, 0)
+	for _, entry := range Expired(entries, now, 30) {
+		expired = append(expired, entry.Name)
+	}
+	// 2026-01-01 is older than 30 days, but the first backup of January
+	if expected := []string{"2025-11-20", "2025-12-15", "2025-12-31"}; !reflect.DeepEqual(expired, expected) {
+		t.Errorf("expected %v, got %v", expected, expired)
+	}
+}
+
+func TestList(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"2026-01-02", "2025-12-01", "tmp"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0770); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"2025-11-01.ods", "2025-11-02.txt", "notes.ods"} {
+		if err := os.WriteFile(filepath.Join(root, file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	if expected := []string{"2025-11-01.ods", "2025-12-01", "2026-01-02"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+const testOdsContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet>
+<table:table table:name="Tags">
+<table:table-row><table:table-cell><text:p>TAG</text:p></table:table-cell><table:table-cell><text:p>NAME</text:p></table:table-cell><table:table-cell table:number-columns-repeated="1000"/></table:table-row>
+<table:table-row table:number-rows-repeated="2"><table:table-cell table:number-columns-repeated="1000"/></table:table-row>
+<table:table-row><table:table-cell table:number-columns-repeated="2"><text:p>a<text:s text:c="2"/>b</text:p><text:p>c</text:p></table:table-cell><table:table-cell/><table:table-cell><office:annotation><text:p>note</text:p></office:annotation><text:p>x</text:p></table:table-cell></table:table-row>
+<table:table-row table:number-rows-repeated="100000"><table:table-cell table:number-columns-repeated="1000"/></table:table-row>
+</table:table>
+</office:spreadsheet></office:body>
+</office:document-content>`
+
+func writeTestOds(t *testing.T, fileName string) {
+	f, err := os.Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{"mimetype": odsMime, "content.xml": testOdsContent} {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+}
+
+func TestLoadOds(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "test.ods")
+	writeTestOds(t, fileName)
+
+	if err := verifyOds(fileName); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := LoadOds(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{{"TAG", "NAME"}, {}, {}, {"a  b\nc", "a  b\nc", "", "x"}}
+	if !reflect.DeepEqual(snapshot.Sheets, []string{"Tags"}) || !reflect.DeepEqual(snapshot.Tables["Tags"], expected) {
+		t.Errorf("unexpected tables %v %q", snapshot.Sheets, snapshot.Tables)
+	}
+}
+
+func TestLoadSource(t *testing.T) {
+	root := t.TempDir()
+	odsDir := filepath.Join(root, "2025-02-01")
+	if err := os.Mkdir(odsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestOds(t, filepath.Join(odsDir, OdsFile))
+	legacy := filepath.Join(root, "2025-01-31.ods")
+	writeTestOds(t, legacy)
+
+	for source, date := range map[string]string{odsDir: "2025-02-01", legacy: "2025-01-31"} {
+		snapshot, err := LoadSource(source)
+		if err != nil {
+			t.Fatalf("%s: %v", source, err)
+		}
+		if !reflect.DeepEqual(snapshot.Sheets, []string{"Tags"}) {
+			t.Errorf("%s: unexpected sheets %v", source, snapshot.Sheets)
+		}
+		d, err := SourceDate(source)
+		if err != nil || d.Format(DateFormat) != date {
+			t.Errorf("%s: expected date %s, got %v, %v", source, date, d, err)
+		}
+	}
+	if _, err := SourceDate(filepath.Join(root, "latest")); err == nil {
+		t.Errorf("expected error for a backup without date")
+	}
+}
@@ -0,0 +1,170 @@
+package backup
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+)
+
+const (
+	odsTableNs  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	odsTextNs   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	odsMaxCells = 26 // the Sheets API reads the columns A to Z
+)
+
+func odsAttr(e xml.StartElement, space string, local string) string {
+	for _, attr := range e.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func odsRepeat(e xml.StartElement, local string) int {
+	n, err := strconv.Atoi(odsAttr(e, odsTableNs, local))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// odsTables parses the tables of an ODS content.xml; cells hold the displayed text, like the Sheets API returns it.
+func odsTables(r io.Reader) (*events.Snapshot, error) {
+	snapshot := &events.Snapshot{Sheets: make([]string, 0), Tables: make(map[string][][]string)}
+	decoder := xml.NewDecoder(r)
+
+	var (
+		table        string
+		rows         [][]string
+		emptyRows    int // pending empty rows (only added if a non-empty row follows)
+		row          []string
+		emptyCells   int // pending empty cells
+		rowRepeat    int
+		cellRepeat   int
+		inCell       bool
+		cell         strings.Builder
+		paragraphs   int
+		inParagraph  bool
+		skipElements int // annotations are not part of the cell text
+	)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return snapshot, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skipElements > 0 || (t.Name.Space == "urn:oasis:names:tc:opendocument:xmlns:office:1.0" && t.Name.Local == "annotation") {
+				skipElements += 1
+				continue
+			}
+			switch {
+			case t.Name.Space == odsTableNs && t.Name.Local == "table":
+				table = odsAttr(t, odsTableNs, "name")
+				rows = make([][]string, 0)
+				emptyRows = 0
+			case t.Name.Space == odsTableNs && t.Name.Local == "table-row":
+				row = make([]string, 0)
+				emptyCells = 0
+				rowRepeat = odsRepeat(t, "number-rows-repeated")
+			case t.Name.Space == odsTableNs && (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell"):
+				inCell = true
+				cell.Reset()
+				paragraphs = 0
+				cellRepeat = odsRepeat(t, "number-columns-repeated")
+			case inCell && t.Name.Space == odsTextNs && t.Name.Local == "p":
+				if paragraphs > 0 {
+					cell.WriteString("\n")
+				}
+				paragraphs += 1
+				inParagraph = true
+			case inParagraph && t.Name.Space == odsTextNs && t.Name.Local == "s":
+				n, err := strconv.Atoi(odsAttr(t, odsTextNs, "c"))
+				if err != nil || n < 1 {
+					n = 1
+				}
+				cell.WriteString(strings.Repeat(" ", n))
+			case inParagraph && t.Name.Space == odsTextNs && t.Name.Local == "tab":
+				cell.WriteString("\t")
+			case inParagraph && t.Name.Space == odsTextNs && t.Name.Local == "line-break":
+				cell.WriteString("\n")
+			}
+		case xml.CharData:
+			if inParagraph && skipElements == 0 {
+				cell.Write(t)
+			}
+		case xml.EndElement:
+			if skipElements > 0 {
+				skipElements -= 1
+				continue
+			}
+			switch {
+			case t.Name.Space == odsTextNs && t.Name.Local == "p":
+				inParagraph = false
+			case t.Name.Space == odsTableNs && (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell"):
+				inCell = false
+				value := cell.String()
+				if value == "" {
+					emptyCells += cellRepeat
+					continue
+				}
+				for ; emptyCells > 0 && len(row) < odsMaxCells; emptyCells-- {
+					row = append(row, "")
+				}
+				emptyCells = 0
+				for i := 0; i < cellRepeat && len(row) < odsMaxCells; i++ {
+					row = append(row, value)
+				}
+			case t.Name.Space == odsTableNs && t.Name.Local == "table-row":
+				if len(row) == 0 {
+					emptyRows += rowRepeat
+					continue
+				}
+				for ; emptyRows > 0; emptyRows-- {
+					rows = append(rows, []string{})
+				}
+				for i := 0; i < rowRepeat; i++ {
+					rows = append(rows, append([]string{}, row...))
+				}
+			case t.Name.Space == odsTableNs && t.Name.Local == "table":
+				snapshot.Sheets = append(snapshot.Sheets, table)
+				snapshot.Tables[table] = rows
+			}
+		}
+	}
+}
+
+// LoadOds reads the tables of an ODS file (e.g. the Drive export of a backup) as a snapshot.
+func LoadOds(fileName string) (*events.Snapshot, error) {
+	r, err := zip.OpenReader(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open ods file '%s': %w", fileName, err)
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.Name != "content.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open content of '%s': %w", fileName, err)
+		}
+		defer rc.Close()
+		snapshot, err := odsTables(rc)
+		if err != nil {
+			return nil, fmt.Errorf("parse content of '%s': %w", fileName, err)
+		}
+		return snapshot, nil
+	}
+	return nil, fmt.Errorf("ods file '%s' has no content.xml", fileName)
+}
@@ -0,0 +1,80 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Entry is a backup in the backup root: a directory named by its date or a (legacy) file 'YYYY-MM-DD.ods'.
+type Entry struct {
+	Name string
+	Date time.Time
+}
+
+// List returns the backups in root, oldest first; other files are ignored.
+func List(root string) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("list backups: %w", err)
+	}
+	entries := make([]Entry, 0, len(dirEntries))
+	for _, e := range dirEntries {
+		name := e.Name()
+		if len(name) < len(DateFormat) {
+			continue
+		}
+		date, err := time.Parse(DateFormat, name[:len(DateFormat)])
+		if err != nil {
+			continue
+		}
+		if e.IsDir() != (name == date.Format(DateFormat)) {
+			continue
+		}
+		if !e.IsDir() && filepath.Ext(name) != ".ods" {
+			continue
+		}
+		entries = append(entries, Entry{name, date})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries, nil
+}
+
+// Expired returns the backups to delete: backups of the last keepDays days are kept, older ones only if they are the
+// first backup of their month.
+func Expired(entries []Entry, now time.Time, keepDays int) []Entry {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	limit := today.AddDate(0, 0, -keepDays)
+	months := make(map[string]bool)
+	expired := make([]Entry, 0)
+	for _, entry := range entries {
+		month := entry.Date.Format("2006-01")
+		first := !months[month]
+		months[month] = true
+		if entry.Date.After(limit) || first {
+			continue
+		}
+		expired = append(expired, entry)
+	}
+	return expired
+}
+
+// Prune deletes the expired backups of root and returns their names.
+func Prune(root string, now time.Time, keepDays int) ([]string, error) {
+	entries, err := List(root)
+	if err != nil {
+		return nil, err
+	}
+	removed := make([]string, 0)
+	for _, entry := range Expired(entries, now, keepDays) {
+		if err := os.RemoveAll(filepath.Join(root, entry.Name)); err != nil {
+			return removed, fmt.Errorf("remove backup '%s': %w", entry.Name, err)
+		}
+		removed = append(removed, entry.Name)
+	}
+	return removed, nil
+}
@@ -0,0 +1,313 @@
+// Package diff compares two versions of the events data semantically: added, removed and renamed entries, changed
+// fields, and tag and series membership.
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+)
+
+// Entry identifies an event, group or shop.
+type Entry struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	Date string `json:"date,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Change is an entry present in both versions; OldName and OldSlug are set for renamed entries.
+type Change struct {
+	Entry
+	OldName       string        `json:"old_name,omitempty"`
+	OldSlug       string        `json:"old_slug,omitempty"`
+	MatchedBy     string        `json:"matched_by"` // uuid, slug history, identity
+	Fields        []FieldChange `json:"fields,omitempty"`
+	TagsAdded     []string      `json:"tags_added,omitempty"`
+	TagsRemoved   []string      `json:"tags_removed,omitempty"`
+	SeriesAdded   []string      `json:"series_added,omitempty"`
+	SeriesRemoved []string      `json:"series_removed,omitempty"`
+}
+
+func (c Change) Renamed() bool {
+	return c.OldSlug != ""
+}
+
+func (c Change) empty() bool {
+	return !c.Renamed() && len(c.Fields) == 0 && len(c.TagsAdded) == 0 && len(c.TagsRemoved) == 0 &&
+		len(c.SeriesAdded) == 0 && len(c.SeriesRemoved) == 0
+}
+
+type Result struct {
+	Added         []Entry  `json:"added"`
+	Removed       []Entry  `json:"removed"`
+	Changed       []Change `json:"changed"` // renamed or modified entries
+	TagsAdded     []string `json:"tags_added"`
+	TagsRemoved   []string `json:"tags_removed"`
+	SeriesAdded   []string `json:"series_added"`
+	SeriesRemoved []string `json:"series_removed"`
+}
+
+func (r Result) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0 && len(r.TagsAdded) == 0 &&
+		len(r.TagsRemoved) == 0 && len(r.SeriesAdded) == 0 && len(r.SeriesRemoved) == 0
+}
+
+func entries(data events.Data) []*events.Event {
+	res := make([]*events.Event, 0)
+	for _, eventList := range [][]*events.Event{data.Events, data.EventsOld, data.EventsObsolete, data.Groups, data.GroupsObsolete, data.Shops, data.ShopsObsolete} {
+		for _, event := range eventList {
+			if !event.IsSeparator() {
+				res = append(res, event)
+			}
+		}
+	}
+	return res
+}
+
+func entry(event *events.Event) Entry {
+	return Entry{event.Type, event.Name.Orig, event.Slug(), event.Time.Original}
+}
+
+func uuid(event *events.Event) string {
+	uid, err := event.GetUUID()
+	if err != nil {
+		return ""
+	}
+	return uid.String()
+}
+
+func mainUrl(event *events.Event) string {
+	if event.MainLink == nil {
+		return ""
+	}
+	return event.MainLink.Url
+}
+
+func status(event *events.Event) string {
+	flags := make([]string, 0)
+	if event.Cancelled {
+		flags = append(flags, "cancelled")
+	}
+	if event.Obsolete {
+		flags = append(flags, "obsolete")
+	}
+	if event.Special {
+		flags = append(flags, "special")
+	}
+	if event.Status != "" {
+		flags = append(flags, event.Status)
+	}
+	return strings.Join(flags, ", ")
+}
+
+func links(event *events.Event) string {
+	s := make([]string, 0, len(event.Links))
+	for _, link := range event.Links {
+		s = append(s, fmt.Sprintf("%s <%s>", link.Name, link.Url))
+	}
+	return strings.Join(s, ", ")
+}
+
+// fields are the compared fields of an entry.
+var fields = []struct {
+	name  string
+	value func(event *events.Event) string
+}{
+	{"name", func(e *events.Event) string { return e.Name.Orig }},
+	{"date", func(e *events.Event) string { return e.Time.Original }},
+	{"place", func(e *events.Event) string { return e.Location.NameNoFlag() }},
+	{"coordinates", func(e *events.Event) string { return e.Location.Geo }},
+	{"status", status},
+	{"url", mainUrl},
+	{"links", links},
+	{"description", func(e *events.Event) string { return string(e.Details) }},
+}
+
+// setDiff returns the elements only in b and the elements only in a.
+func setDiff(a []string, b []string) (added []string, removed []string) {
+	added, removed = make([]string, 0), make([]string, 0)
+	inA := make(map[string]bool)
+	for _, s := range a {
+		inA[s] = true
+	}
+	inB := make(map[string]bool)
+	for _, s := range b {
+		inB[s] = true
+		if !inA[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range a {
+		if !inB[s] {
+			removed = append(removed, s)
+		}
+	}
+	return added, removed
+}
+
+func tagNames(event *events.Event) []string {
+	names := make([]string, 0, len(event.Tags))
+	for _, tag := range event.Tags {
+		names = append(names, tag.Name.Orig)
+	}
+	return names
+}
+
+func serieNames(event *events.Event) []string {
+	names := make([]string, 0, len(event.Series))
+	for _, serie := range event.Series {
+		names = append(names, serie.Name.Orig)
+	}
+	return names
+}
+
+func compare(oldEvent *events.Event, newEvent *events.Event, matchedBy string) Change {
+	change := Change{Entry: entry(newEvent), MatchedBy: matchedBy}
+	if oldEvent.Slug() != newEvent.Slug() {
+		change.OldName = oldEvent.Name.Orig
+		change.OldSlug = oldEvent.Slug()
+	}
+	for _, field := range fields {
+		if a, b := field.value(oldEvent), field.value(newEvent); a != b {
+			change.Fields = append(change.Fields, FieldChange{field.name, a, b})
+		}
+	}
+	change.TagsAdded, change.TagsRemoved = setDiff(tagNames(oldEvent), tagNames(newEvent))
+	change.SeriesAdded, change.SeriesRemoved = setDiff(serieNames(oldEvent), serieNames(newEvent))
+	return change
+}
+
+// slugGroups maps each slug of the registry to its identity, so that all former slugs of an entry match.
+func slugGroups(registry *events.SlugRegistry) map[string]string {
+	groups := make(map[string]string)
+	if registry == nil {
+		return groups
+	}
+	for identity, slugs := range registry.Entries {
+		for _, slug := range slugs {
+			groups[slug] = identity
+		}
+	}
+	return groups
+}
+
+// Compare compares the entries, tags and series of oldData and newData. Entries are matched by UUID (i.e. slug), then
+// by the slug history of registry (may be nil), then by identity (type, year and main URL) if it is unique on both
+// sides.
+func Compare(oldData events.Data, newData events.Data, registry *events.SlugRegistry) Result {
+	oldEntries, newEntries := entries(oldData), entries(newData)
+	matchedOld := make(map[*events.Event]bool)
+	matchedNew := make(map[*events.Event]bool)
+	changes := make([]Change, 0)
+	match := func(a *events.Event, b *events.Event, matchedBy string) {
+		matchedOld[a] = true
+		matchedNew[b] = true
+		if change := compare(a, b, matchedBy); !change.empty() {
+			changes = append(changes, change)
+		}
+	}
+
+	byUuid := make(map[string]*events.Event)
+	for _, event := range newEntries {
+		byUuid[uuid(event)] = event
+	}
+	for _, event := range oldEntries {
+		if other, found := byUuid[uuid(event)]; found && !matchedNew[other] {
+			match(event, other, "uuid")
+		}
+	}
+
+	groups := slugGroups(registry)
+	byGroup := make(map[string]*events.Event)
+	for _, event := range newEntries {
+		if group, found := groups[event.Slug()]; found && !matchedNew[event] {
+			byGroup[group] = event
+		}
+	}
+	for _, event := range oldEntries {
+		if matchedOld[event] {
+			continue
+		}
+		if group, found := groups[event.Slug()]; found {
+			if other, found := byGroup[group]; found && !matchedNew[other] {
+				match(event, other, "slug history")
+			}
+		}
+	}
+
+	uniqueIdentities := func(eventList []*events.Event, matched map[*events.Event]bool) map[string]*events.Event {
+		count := make(map[string]int)
+		byIdentity := make(map[string]*events.Event)
+		for _, event := range eventList {
+			if !matched[event] {
+				count[event.Identity()] += 1
+				byIdentity[event.Identity()] = event
+			}
+		}
+		for identity, n := range count {
+			if n > 1 {
+				delete(byIdentity, identity)
+			}
+		}
+		return byIdentity
+	}
+	oldByIdentity := uniqueIdentities(oldEntries, matchedOld)
+	newByIdentity := uniqueIdentities(newEntries, matchedNew)
+	for _, event := range oldEntries {
+		if matchedOld[event] || oldByIdentity[event.Identity()] != event {
+			continue
+		}
+		if other, found := newByIdentity[event.Identity()]; found {
+			match(event, other, "identity")
+		}
+	}
+
+	result := Result{
+		Added:   make([]Entry, 0),
+		Removed: make([]Entry, 0),
+		Changed: changes,
+	}
+	for _, event := range newEntries {
+		if !matchedNew[event] {
+			result.Added = append(result.Added, entry(event))
+		}
+	}
+	for _, event := range oldEntries {
+		if !matchedOld[event] {
+			result.Removed = append(result.Removed, entry(event))
+		}
+	}
+	for _, list := range [][]Entry{result.Added, result.Removed} {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Slug < list[j].Slug })
+	}
+	sort.SliceStable(result.Changed, func(i, j int) bool { return result.Changed[i].Slug < result.Changed[j].Slug })
+
+	result.TagsAdded, result.TagsRemoved = setDiff(tagList(oldData), tagList(newData))
+	result.SeriesAdded, result.SeriesRemoved = setDiff(serieList(oldData), serieList(newData))
+	return result
+}
+
+func tagList(data events.Data) []string {
+	names := make([]string, 0, len(data.Tags))
+	for _, tag := range data.Tags {
+		names = append(names, tag.Name.Orig)
+	}
+	return names
+}
+
+func serieList(data events.Data) []string {
+	names := make([]string, 0, len(data.Series)+len(data.SeriesOld))
+	for _, serie := range append(append([]*events.Serie{}, data.Series...), data.SeriesOld...) {
+		names = append(names, serie.Name.Orig)
+	}
+	return names
+}
@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+)
+
+var eventColumns = []string{"DATE", "NAME", "NAME2", "SEO", "STATUS", "URL", "DESCRIPTION", "LOCATION", "COORDINATES", "REGISTRATION", "TAGS", "LINK1"}
+
+func testData(t *testing.T, events2026 [][]string) events.Data {
+	snapshot := &events.Snapshot{
+		Sheets: []string{"Events2025", "Events2026", "Groups", "Shops", "Parkrun", "Tags", "Series"},
+		Tables: map[string][][]string{
+			"Events2025": {eventColumns},
+			"Events2026": append([][]string{eventColumns}, events2026...),
+			"Groups":     {eventColumns},
+			"Shops":      {eventColumns},
+			"Parkrun":    {{"DATE", "INDEX", "RUNNERS", "TEMP", "SPECIAL", "CAFE", "RESULTS", "REPORT", "AUTHOR", "PHOTOS"}},
+			"Tags":       {{"TAG", "NAME", "DESCRIPTION"}},
+			"Series":     {{"NAME", "DESCRIPTION", "LINK1"}},
+		},
+	}
+	data, err := events.FetchDataFrom(snapshot, time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestCompare(t *testing.T) {
+	oldData := testData(t, [][]string{
+		{"17.05.2026", "Odenwald Trail", "", "", "", "https://example.com/trail", "", "Wald-Michelbach", "", "", "Traillauf", ""},
+		{"07.06.2026", "Brückenlauf", "", "", "", "https://example.com/bruecke", "", "Mannheim", "", "", "", ""},
+		{"14.06.2026", "Stadtlauf", "", "", "", "https://example.com/stadt", "", "Heidelberg", "", "", "", ""},
+	})
+	newData := testData(t, [][]string{
+		{"17.05.2026", "Odenwald Trail", "", "", "", "https://example.com/trail", "", "Wald-Michelbach", "", "", "Bergauf,serie:Trailcup", "Ergebnisse|https://example.com/res"},
+		{"08.06.2026", "Neckarbrückenlauf", "", "", "abgesagt", "https://www.example.com/bruecke/", "", "Mannheim", "", "", "", ""},
+		{"21.06.2026", "Waldlauf", "", "", "", "https://example.com/wald", "", "Schwetzingen", "", "", "", ""},
+	})
+
+	result := Compare(oldData, newData, nil)
+	if len(result.Added) != 1 || result.Added[0].Name != "Waldlauf" {
+		t.Errorf("unexpected added %v", result.Added)
+	}
+	if len(result.Removed) != 1 || result.Removed[0].Name != "Stadtlauf" {
+		t.Errorf("unexpected removed %v", result.Removed)
+	}
+	if len(result.Changed) != 2 {
+		t.Fatalf("expected 2 changes, got %v", result.Changed)
+	}
+
+	renamed := result.Changed[0]
+	if !renamed.Renamed() || renamed.OldName != "Brückenlauf" || renamed.MatchedBy != "identity" {
+		t.Errorf("expected rename of 'Brückenlauf', got %+v", renamed)
+	}
+	changedFields := make([]string, 0)
+	for _, field := range renamed.Fields {
+		changedFields = append(changedFields, field.Field)
+	}
+	if s := strings.Join(changedFields, ","); s != "name,date,status,url" {
+		t.Errorf("unexpected changed fields %s", s)
+	}
+
+	trail := result.Changed[1]
+	if trail.Renamed() || trail.MatchedBy != "uuid" {
+		t.Errorf("unexpected change %+v", trail)
+	}
+	if len(trail.TagsAdded) != 1 || trail.TagsAdded[0] != "bergauf" || len(trail.TagsRemoved) != 1 || len(trail.SeriesAdded) != 1 {
+		t.Errorf("unexpected membership changes %+v", trail)
+	}
+	if len(result.TagsAdded) != 1 || result.TagsAdded[0] != "bergauf" || len(result.TagsRemoved) != 1 || result.TagsRemoved[0] != "traillauf" {
+		t.Errorf("unexpected tags added %v, removed %v", result.TagsAdded, result.TagsRemoved)
+	}
+
+	var buffer bytes.Buffer
+	if err := result.WriteMarkdown(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), "renamed from 'Brückenlauf'") || !strings.Contains(buffer.String(), "1 added, 1 removed, 2 changed (1 renamed)") {
+		t.Errorf("unexpected markdown:\n%s", buffer.String())
+	}
+}
+
+func TestCompareSlugHistory(t *testing.T) {
+	oldData := testData(t, [][]string{
+		{"14.06.2026", "Stadtlauf", "", "", "", "https://example.com/stadt", "", "Heidelberg", "", "", "", ""},
+	})
+	newData := testData(t, [][]string{
+		{"14.06.2026", "Altstadtlauf", "", "", "", "https://example.com/altstadt", "", "Heidelberg", "", "", "", ""},
+	})
+
+	if result := Compare(oldData, newData, nil); len(result.Added) != 1 || len(result.Removed) != 1 {
+		t.Errorf("expected unrelated entries without slug history, got %+v", result)
+	}
+
+	registry := &events.SlugRegistry{Entries: map[string][]string{
+		"event|2026|example.com/altstadt": {oldData.Events[1].Slug(), newData.Events[1].Slug()},
+	}}
+	result := Compare(oldData, newData, registry)
+	if len(result.Added) != 0 || len(result.Removed) != 0 || len(result.Changed) != 1 || result.Changed[0].MatchedBy != "slug history" {
+		t.Errorf("expected rename via slug history, got %+v", result)
+	}
+}
@@ -0,0 +1,159 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxValueLength limits the length of field values in the text and Markdown output (descriptions can be long).
+const maxValueLength = 120
+
+func shorten(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len([]rune(s)) > maxValueLength {
+		return string([]rune(s)[:maxValueLength-1]) + "…"
+	}
+	return s
+}
+
+func (e Entry) String() string {
+	if e.Date != "" {
+		return fmt.Sprintf("%s '%s' (%s, /%s)", e.Type, e.Name, e.Date, e.Slug)
+	}
+	return fmt.Sprintf("%s '%s' (/%s)", e.Type, e.Name, e.Slug)
+}
+
+// details returns the changes of c as lines.
+func (c Change) details() []string {
+	lines := make([]string, 0)
+	if c.Renamed() {
+		lines = append(lines, fmt.Sprintf("renamed from '%s' (/%s, matched by %s)", c.OldName, c.OldSlug, c.MatchedBy))
+	}
+	for _, field := range c.Fields {
+		if c.Renamed() && field.Field == "name" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: '%s' -> '%s'", field.Field, shorten(field.Old), shorten(field.New)))
+	}
+	if len(c.TagsAdded) > 0 {
+		lines = append(lines, "tags added: "+strings.Join(c.TagsAdded, ", "))
+	}
+	if len(c.TagsRemoved) > 0 {
+		lines = append(lines, "tags removed: "+strings.Join(c.TagsRemoved, ", "))
+	}
+	if len(c.SeriesAdded) > 0 {
+		lines = append(lines, "added to series: "+strings.Join(c.SeriesAdded, ", "))
+	}
+	if len(c.SeriesRemoved) > 0 {
+		lines = append(lines, "removed from series: "+strings.Join(c.SeriesRemoved, ", "))
+	}
+	return lines
+}
+
+func (r Result) summary() string {
+	renamed := 0
+	for _, change := range r.Changed {
+		if change.Renamed() {
+			renamed += 1
+		}
+	}
+	return fmt.Sprintf("%d added, %d removed, %d changed (%d renamed)", len(r.Added), len(r.Removed), len(r.Changed), renamed)
+}
+
+// section is a titled list of the output.
+type section struct {
+	title string
+	items []string
+	sub   [][]string // optional details per item
+}
+
+func (r Result) sections() []section {
+	sections := make([]section, 0)
+	entryItems := func(entries []Entry) []string {
+		items := make([]string, 0, len(entries))
+		for _, e := range entries {
+			items = append(items, e.String())
+		}
+		return items
+	}
+	sections = append(sections, section{"Added", entryItems(r.Added), nil})
+	sections = append(sections, section{"Removed", entryItems(r.Removed), nil})
+	changed := section{"Changed", make([]string, 0), make([][]string, 0)}
+	for _, change := range r.Changed {
+		changed.items = append(changed.items, change.Entry.String())
+		changed.sub = append(changed.sub, change.details())
+	}
+	sections = append(sections, changed)
+	sections = append(sections, section{"Tags added", r.TagsAdded, nil})
+	sections = append(sections, section{"Tags removed", r.TagsRemoved, nil})
+	sections = append(sections, section{"Series added", r.SeriesAdded, nil})
+	sections = append(sections, section{"Series removed", r.SeriesRemoved, nil})
+	return sections
+}
+
+// WriteText writes the result as plain text.
+func (r Result) WriteText(w io.Writer) error {
+	var b strings.Builder
+	if r.Empty() {
+		b.WriteString("no changes\n")
+	}
+	for _, s := range r.sections() {
+		if len(s.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:\n", s.title)
+		for i, item := range s.items {
+			fmt.Fprintf(&b, "  %s\n", item)
+			if s.sub != nil {
+				for _, line := range s.sub[i] {
+					fmt.Fprintf(&b, "    %s\n", line)
+				}
+			}
+		}
+	}
+	if !r.Empty() {
+		fmt.Fprintf(&b, "%s\n", r.summary())
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMarkdown writes the result as Markdown (e.g. for a pull request).
+func (r Result) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Data changes\n\n")
+	if r.Empty() {
+		b.WriteString("No changes.\n")
+	} else {
+		fmt.Fprintf(&b, "%s\n", r.summary())
+	}
+	escape := strings.NewReplacer("*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`)
+	for _, s := range r.sections() {
+		if len(s.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n#### %s\n\n", s.title)
+		for i, item := range s.items {
+			fmt.Fprintf(&b, "- %s\n", escape.Replace(item))
+			if s.sub != nil {
+				for _, line := range s.sub[i] {
+					fmt.Fprintf(&b, "  - %s\n", escape.Replace(line))
+				}
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJson writes the result as JSON.
+func (r Result) WriteJson(w io.Writer) error {
+	buf, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal diff: %w", err)
+	}
+	_, err = w.Write(append(buf, '\n'))
+	return err
+}
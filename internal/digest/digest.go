@@ -0,0 +1,177 @@
+package digest
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"sort"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+//go:embed digest.html
+var htmlTemplate string
+
+//go:embed digest.txt
+var textTemplate string
+
+type Options struct {
+	Now          time.Time
+	Days         int // events starting within the next Days days
+	DeadlineDays int // registration deadlines within the next DeadlineDays days
+	BaseUrl      utils.Url
+}
+
+// Entry is an event as shown in the digest.
+type Entry struct {
+	Name      string
+	Date      string
+	Place     string
+	Url       string
+	Cancelled bool
+}
+
+type Deadline struct {
+	Entry
+	Deadline string
+}
+
+type Digest struct {
+	Subject   string
+	Intro     string
+	BaseUrl   string
+	Upcoming  []Entry
+	Added     []Entry
+	Deadlines []Deadline
+}
+
+func (d Digest) IsEmpty() bool {
+	return len(d.Upcoming) == 0 && len(d.Added) == 0 && len(d.Deadlines) == 0
+}
+
+// FormatDate formats a date like "Samstag, 25. Oktober".
+func FormatDate(t time.Time) string {
+	return fmt.Sprintf("%s, %d. %s", utils.WeekdayStr(t.Weekday()), t.Day(), utils.MonthStr(t.Month()))
+}
+
+func createEntry(event *events.Event, baseUrl utils.Url) Entry {
+	return Entry{
+		event.Name.Orig,
+		event.Time.Formatted,
+		event.Location.NameNoFlag(),
+		baseUrl.Join(event.Slug()),
+		event.Cancelled,
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// Build collects the upcoming events, the events not contained in known (i.e. added since the last digest) and the upcoming registration deadlines.
+func Build(data events.Data, known State, options Options) Digest {
+	today := startOfDay(options.Now)
+	end := today.AddDate(0, 0, options.Days)
+	deadlineEnd := today.AddDate(0, 0, options.DeadlineDays)
+
+	d := Digest{
+		Subject: fmt.Sprintf("heidelberg.run: Laufveranstaltungen bis %s", FormatDate(end.AddDate(0, 0, -1))),
+		Intro:   fmt.Sprintf("Laufveranstaltungen im Raum Heidelberg von %s bis %s", FormatDate(today), FormatDate(end.AddDate(0, 0, -1))),
+		BaseUrl: string(options.BaseUrl),
+	}
+
+	deadlines := make([]*events.Event, 0)
+	for _, event := range data.Events {
+		if event.IsSeparator() {
+			continue
+		}
+		if !event.Time.IsZero() && event.Time.From.Before(end) && !event.Time.To.Before(today) {
+			d.Upcoming = append(d.Upcoming, createEntry(event, options.BaseUrl))
+		}
+		if known != nil && !known.Contains(event.Slug()) {
+			d.Added = append(d.Added, createEntry(event, options.BaseUrl))
+		}
+		if !event.Cancelled && !event.Deadline.IsZero() && !event.Deadline.Before(today) && event.Deadline.Before(deadlineEnd) {
+			deadlines = append(deadlines, event)
+		}
+	}
+
+	sort.SliceStable(deadlines, func(i, j int) bool {
+		return deadlines[i].Deadline.Before(deadlines[j].Deadline)
+	})
+	for _, event := range deadlines {
+		d.Deadlines = append(d.Deadlines, Deadline{createEntry(event, options.BaseUrl), FormatDate(event.Deadline)})
+	}
+
+	return d
+}
+
+func (d Digest) Html() (string, error) {
+	t, err := htmltemplate.New("digest.html").Parse(htmlTemplate)
+	if err != nil {
+		return "", fmt.Errorf("parse html template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("render html template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func (d Digest) Text() (string, error) {
+	t, err := texttemplate.New("digest.txt").Parse(textTemplate)
+	if err != nil {
+		return "", fmt.Errorf("parse text template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, d); err != nil {
+		return "", fmt.Errorf("render text template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// State is the set of event slugs that were already known when the last digest was sent.
+type State map[string]bool
+
+func (s State) Contains(slug string) bool {
+	return s[slug]
+}
+
+// LoadState reads the state file; a missing file results in a nil state (i.e. no event is considered to be new).
+func LoadState(fileName string) (State, error) {
+	buf, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load digest state: %w", err)
+	}
+	var slugs []string
+	if err := json.Unmarshal(buf, &slugs); err != nil {
+		return nil, fmt.Errorf("parse digest state %s: %w", fileName, err)
+	}
+	state := make(State, len(slugs))
+	for _, slug := range slugs {
+		state[slug] = true
+	}
+	return state, nil
+}
+
+// SaveState stores the slugs of all current events.
+func SaveState(fileName string, data events.Data) error {
+	slugs := make([]string, 0, len(data.Events))
+	for _, event := range data.Events {
+		if !event.IsSeparator() {
+			slugs = append(slugs, event.Slug())
+		}
+	}
+	sort.Strings(slugs)
+	return utils.WriteJSON(fileName, slugs)
+}
@@ -0,0 +1,144 @@
+package digest
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func createEvent(t *testing.T, name string, date string) *events.Event {
+	timeRange, err := utils.CreateTimeRange(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &events.Event{Type: "event", Name: utils.NewName(name), Time: timeRange, Location: events.CreateLocation("Heidelberg", "")}
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2025, 10, 20, 9, 0, 0, 0, time.Local)
+	tomorrow := createEvent(t, "Morgen", "21.10.2025")
+	later := createEvent(t, "Später", "15.11.2025")
+	later.Deadline = time.Date(2025, 10, 30, 0, 0, 0, 0, time.Local)
+	added := createEvent(t, "Neu", "24.10.2025")
+	data := events.Data{Events: []*events.Event{tomorrow, added, later}}
+	state := State{tomorrow.Slug(): true, later.Slug(): true}
+
+	d := Build(data, state, Options{now, 7, 14, utils.Url("https://heidelberg.run")})
+	if len(d.Upcoming) != 2 || d.Upcoming[0].Name != "Morgen" || d.Upcoming[1].Name != "Neu" {
+		t.Errorf("unexpected upcoming events %+v", d.Upcoming)
+	}
+	if len(d.Added) != 1 || d.Added[0].Name != "Neu" {
+		t.Errorf("unexpected added events %+v", d.Added)
+	}
+	if len(d.Deadlines) != 1 || d.Deadlines[0].Deadline != "Donnerstag, 30. Oktober" {
+		t.Errorf("unexpected deadlines %+v", d.Deadlines)
+	}
+
+	// without state, no event is considered to be new
+	if d := Build(data, nil, Options{now, 7, 14, utils.Url("https://heidelberg.run")}); len(d.Added) != 0 {
+		t.Errorf("expected no added events without state, got %+v", d.Added)
+	}
+
+	text, err := d.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "https://heidelberg.run/event/2025-morgen.html") {
+		t.Errorf("text misses event link:\n%s", text)
+	}
+	if _, err := d.Html(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeSmtpServer accepts a single mail and returns its data via the channel.
+func fakeSmtpServer(t *testing.T) (string, int, <-chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				received <- data.String()
+				reply("250 ok")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p, received
+}
+
+func TestSend(t *testing.T) {
+	host, port, received := fakeSmtpServer(t)
+	config := SmtpConfig{Host: host, Port: port, From: "digest@heidelberg.run", To: []string{"runner@example.com", "walker@example.com"}}
+
+	message, err := Message(config.From, "Läufe am Wochenende", "Hallo Läufer", "<p>Hallo Läufer</p>", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Send(config, message); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-received:
+		headers, _, _ := strings.Cut(data, "\r\n\r\n")
+		for _, to := range config.To {
+			if strings.Contains(headers, to) {
+				t.Errorf("headers contain recipient %q:\n%s", to, headers)
+			}
+		}
+		for _, expected := range []string{"To: undisclosed-recipients:;", "Subject: =?utf-8?q?L=C3=A4ufe_am_Wochenende?=", "Content-Type: text/html; charset=utf-8", "Hallo L=C3=A4ufer"} {
+			if !strings.Contains(data, expected) {
+				t.Errorf("mail misses %q:\n%s", expected, data)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail received")
+	}
+}
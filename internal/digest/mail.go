@@ -0,0 +1,99 @@
+package digest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SmtpConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+func LoadSmtpConfig(path string) (SmtpConfig, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return SmtpConfig{}, fmt.Errorf("load smtp config file '%s': %w", path, err)
+	}
+	var config SmtpConfig
+	if err := json.Unmarshal(buf, &config); err != nil {
+		return SmtpConfig{}, fmt.Errorf("unmarshall smtp config data: %w", err)
+	}
+	if config.Host == "" || config.From == "" || len(config.To) == 0 {
+		return SmtpConfig{}, fmt.Errorf("smtp config '%s': 'host', 'from' and 'to' are required", path)
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	return config, nil
+}
+
+func (c SmtpConfig) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, s string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(strings.ReplaceAll(s, "\n", "\r\n"))); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// Message creates a multipart/alternative email with a plain text and an HTML part. The recipients only appear in the
+// SMTP envelope (see Send), so subscribers do not see each other's addresses.
+func Message(from string, subject string, text string, html string, date time.Time) ([]byte, error) {
+	const boundary = "heidelberg-run-digest-boundary"
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: undisclosed-recipients:;\r\n")
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", text},
+		{"text/html", html},
+	} {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		fmt.Fprintf(&buf, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, fmt.Errorf("encode %s part: %w", part.contentType, err)
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// Send delivers the message via the configured SMTP server; authentication is only used if a username is set.
+func Send(config SmtpConfig, message []byte) error {
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	if err := smtp.SendMail(config.Addr(), auth, config.From, config.To, message); err != nil {
+		return fmt.Errorf("send mail via %s: %w", config.Addr(), err)
+	}
+	return nil
+}
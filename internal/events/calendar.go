@@ -3,8 +3,6 @@ package events
 import (
 	"fmt"
 	"net/url"
-	"os"
-	"path/filepath"
 	"time"
 
 	ical "github.com/arran4/golang-ical"
@@ -92,10 +90,7 @@ func CreateCalendar(eventsList []*Event, now time.Time, baseUrl utils.Url, calen
 	}
 
 	serialized := cal.Serialize()
-	if err := os.MkdirAll(filepath.Dir(path), 0770); err != nil {
-		return fmt.Errorf("serializing calender to %s: %w", path, err)
-	}
-	if err := os.WriteFile(path, []byte(serialized), 0o777); err != nil {
+	if _, err := utils.WriteFileIfChanged(path, []byte(serialized)); err != nil {
 		return fmt.Errorf("serializing calender to %s: %w", path, err)
 	}
 
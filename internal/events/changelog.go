@@ -0,0 +1,135 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// ChangelogDays is the number of days changes are kept in the changelog.
+const ChangelogDays = 91
+
+const (
+	ChangeNew       = "new"       // new upcoming event, group or shop
+	ChangeCancelled = "cancelled" // upcoming event was cancelled
+	ChangeDate      = "date"      // upcoming event got a new date
+)
+
+// ChangeRecord is a single change of the data, detected when building the site on Date (YYYY-MM-DD).
+type ChangeRecord struct {
+	Date string `json:"date"`
+	Kind string `json:"kind"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	Old  string `json:"old,omitempty"` // old date (ChangeDate)
+	New  string `json:"new,omitempty"` // new date (ChangeDate), date of new events
+}
+
+// ChangelogEntry is the state of an entry as of the previous build.
+type ChangelogEntry struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Date      string `json:"date,omitempty"`
+	Cancelled bool   `json:"cancelled,omitempty"`
+	Identity  string `json:"identity"`
+}
+
+// Changelog keeps the state of all entries of the previous build (by slug) and the changes of the last ChangelogDays
+// days; each build compares the data with the previous state.
+type Changelog struct {
+	Entries map[string]ChangelogEntry `json:"entries"`
+	Records []ChangeRecord            `json:"records"` // oldest first
+}
+
+// LoadChangelog reads the changelog file; a missing file yields an empty changelog.
+func LoadChangelog(path string) (*Changelog, error) {
+	changelog := &Changelog{make(map[string]ChangelogEntry), make([]ChangeRecord, 0)}
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return changelog, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load changelog file '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(buf, changelog); err != nil {
+		return nil, fmt.Errorf("unmarshall changelog data: %w", err)
+	}
+	if changelog.Entries == nil {
+		changelog.Entries = make(map[string]ChangelogEntry)
+	}
+	if changelog.Records == nil {
+		changelog.Records = make([]ChangeRecord, 0)
+	}
+	return changelog, nil
+}
+
+func (c *Changelog) Save(path string) error {
+	return utils.WriteJSON(path, c)
+}
+
+// Update records the changes of data since the previous build (none for the first build, which only records the
+// state), drops changes older than ChangelogDays and sets data.Changes to the remaining ones.
+func (c *Changelog) Update(data *Data, now time.Time) {
+	today := now.Format("2006-01-02")
+	current := make([]*Event, 0)
+	for _, eventList := range [][]*Event{data.Events, data.EventsOld, data.Groups, data.Shops} {
+		for _, event := range eventList {
+			if !event.IsSeparator() {
+				current = append(current, event)
+			}
+		}
+	}
+
+	// renamed entries are found by their identity, if it is unique
+	byIdentity := make(map[string]string)
+	for slug, entry := range c.Entries {
+		if _, found := byIdentity[entry.Identity]; found {
+			byIdentity[entry.Identity] = ""
+		} else {
+			byIdentity[entry.Identity] = slug
+		}
+	}
+
+	entries := make(map[string]ChangelogEntry)
+	for _, event := range current {
+		slug := event.Slug()
+		entry := ChangelogEntry{event.Type, event.Name.Orig, event.Time.Original, event.Cancelled, event.Identity()}
+		entries[slug] = entry
+		if len(c.Entries) == 0 || event.Old {
+			continue
+		}
+
+		prev, found := c.Entries[slug]
+		if !found {
+			if prevSlug := byIdentity[entry.Identity]; prevSlug != "" {
+				prev, found = c.Entries[prevSlug]
+			}
+		}
+		if !found {
+			c.Records = append(c.Records, ChangeRecord{today, ChangeNew, event.Type, event.Name.Orig, slug, "", event.Time.Original})
+			continue
+		}
+		if event.Cancelled && !prev.Cancelled {
+			c.Records = append(c.Records, ChangeRecord{today, ChangeCancelled, event.Type, event.Name.Orig, slug, "", ""})
+		}
+		if event.Type == "event" && prev.Date != "" && event.Time.Original != "" && prev.Date != event.Time.Original {
+			c.Records = append(c.Records, ChangeRecord{today, ChangeDate, event.Type, event.Name.Orig, slug, prev.Date, event.Time.Original})
+		}
+	}
+	c.Entries = entries
+
+	limit := now.AddDate(0, 0, -ChangelogDays).Format("2006-01-02")
+	records := make([]ChangeRecord, 0, len(c.Records))
+	for _, record := range c.Records {
+		if record.Date >= limit {
+			records = append(records, record)
+		}
+	}
+	c.Records = records
+	data.Changes = append([]ChangeRecord{}, records...)
+}
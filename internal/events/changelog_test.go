@@ -0,0 +1,73 @@
+package events
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChangelog(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "changelog.json")
+	changelog, err := LoadChangelog(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first build only records the state
+	day1 := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	data := Data{Events: []*Event{
+		testEntry(t, "Trail Lauf", "17.05.2026", "https://example.com/trail"),
+		testEntry(t, "Stadtlauf", "14.06.2026", "https://example.com/stadt"),
+	}}
+	changelog.Update(&data, day1)
+	if len(data.Changes) != 0 {
+		t.Errorf("expected no changes for the first build, got %v", data.Changes)
+	}
+	if err := changelog.Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	changelog, err = LoadChangelog(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day2 := day1.AddDate(0, 0, 1)
+	cancelled := testEntry(t, "Stadtlauf", "21.06.2026", "https://example.com/stadt")
+	cancelled.Cancelled = true
+	group := testEntry(t, "Lauftreff Altstadt", "", "https://example.com/lt")
+	group.Type = "group"
+	data = Data{
+		Events: []*Event{
+			testEntry(t, "Odenwald Trail", "17.05.2026", "https://example.com/trail"), // renamed
+			cancelled,
+			testEntry(t, "Maienlauf", "10.05.2026", "https://example.com/mai"),
+		},
+		Groups: []*Event{group},
+	}
+	changelog.Update(&data, day2)
+
+	expected := []ChangeRecord{
+		{"2026-03-03", ChangeCancelled, "event", "Stadtlauf", "event/2026-stadtlauf.html", "", ""},
+		{"2026-03-03", ChangeDate, "event", "Stadtlauf", "event/2026-stadtlauf.html", "14.06.2026", "21.06.2026"},
+		{"2026-03-03", ChangeNew, "event", "Maienlauf", "event/2026-maienlauf.html", "", "10.05.2026"},
+		{"2026-03-03", ChangeNew, "group", "Lauftreff Altstadt", "group/lauftreff-altstadt.html", "", ""},
+	}
+	if len(data.Changes) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, data.Changes)
+	}
+	for i := range expected {
+		if data.Changes[i] != expected[i] {
+			t.Errorf("change %d: expected %v, got %v", i, expected[i], data.Changes[i])
+		}
+	}
+
+	// no new changes; old changes expire
+	changelog.Update(&data, day2.AddDate(0, 0, 1))
+	if len(data.Changes) != len(expected) {
+		t.Errorf("expected unchanged changes, got %v", data.Changes)
+	}
+	changelog.Update(&data, day2.AddDate(0, 0, ChangelogDays+1))
+	if len(data.Changes) != 0 {
+		t.Errorf("expected expired changes, got %v", data.Changes)
+	}
+}
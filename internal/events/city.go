@@ -0,0 +1,151 @@
+package events
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// City is a town with all entries taking place there (by the normalized place name of their location).
+type City struct {
+	Name      utils.Name
+	Country   string
+	Events    []*Event
+	EventsOld []*Event
+	Groups    []*Event
+	Shops     []*Event
+}
+
+func (city *City) Slug() string {
+	return CitySlug(city.Name.Sanitized)
+}
+
+// CitySlug returns the slug of the page of the city with the sanitized name.
+func CitySlug(sanitized string) string {
+	return fmt.Sprintf("ort/%s.html", sanitized)
+}
+
+func (city *City) NumEvents() int {
+	return NonSeparators(city.Events)
+}
+
+func (city *City) NumOldEvents() int {
+	return NonSeparators(city.EventsOld)
+}
+
+func (city *City) NumEntries() int {
+	return city.NumEvents() + city.NumOldEvents() + len(city.Groups) + len(city.Shops)
+}
+
+// HasGeo reports whether any entry of the city has coordinates (for the map).
+func (city *City) HasGeo() bool {
+	for _, list := range [][]*Event{city.Events, city.EventsOld, city.Groups, city.Shops} {
+		for _, event := range list {
+			if event.Location.HasGeo() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cityAliases maps lower case abbreviations to their town. Districts are only resolved in the "Town-District" form
+// (see townOfDistrict), since towns like Rohrbach (Pfalz) or Boxberg share their names with districts of Heidelberg.
+var cityAliases = map[string]string{
+	"hd": "Heidelberg",
+	"ma": "Mannheim",
+}
+
+var reCityDetails = regexp.MustCompile(`\s*\(.*\)\s*$`)
+
+// NormalizeCity returns the town of a location's free text city: without details in parentheses or after a comma,
+// with abbreviations replaced by their town.
+func NormalizeCity(city string) string {
+	city = reCityDetails.ReplaceAllString(city, "")
+	if before, _, found := strings.Cut(city, ","); found {
+		city = before
+	}
+	city = strings.TrimSpace(city)
+	if town, found := cityAliases[strings.ToLower(city)]; found {
+		return town
+	}
+	return city
+}
+
+// townOfDistrict returns the town of a hyphenated place name like "Heidelberg-Handschuhsheim", if the part before
+// the hyphen is a known town (so "Wald-Michelbach" stays as it is).
+func townOfDistrict(place string, towns map[string]string) string {
+	before, _, found := strings.Cut(place, "-")
+	if !found {
+		return place
+	}
+	prefix := strings.TrimSpace(before)
+	if town, found := cityAliases[strings.ToLower(prefix)]; found {
+		return town
+	}
+	if town, found := towns[utils.SanitizeName(prefix)]; found {
+		return town
+	}
+	return place
+}
+
+// collectCities sets the normalized place of all entries and groups them by it.
+func (data *Data) collectCities() {
+	lists := [][]*Event{data.Events, data.EventsOld, data.Groups, data.Shops}
+
+	towns := make(map[string]string)
+	for _, town := range cityAliases {
+		towns[utils.SanitizeName(town)] = town
+	}
+	for _, list := range lists {
+		for _, event := range list {
+			if place := event.Location.Place; place != "" && !strings.Contains(place, "-") {
+				towns[utils.SanitizeName(place)] = place
+			}
+		}
+	}
+
+	cities := make(map[string]*City)
+	for _, list := range lists {
+		for _, event := range list {
+			if event.IsSeparator() || event.Location.Place == "" {
+				continue
+			}
+			place := townOfDistrict(event.Location.Place, towns)
+			name := utils.NewName(place)
+			if name.Sanitized == "" {
+				event.Location.Place = ""
+				continue
+			}
+			event.Location.Place = place
+			city, found := cities[name.Sanitized]
+			if !found {
+				city = &City{name, event.Location.Country, make([]*Event, 0), make([]*Event, 0), make([]*Event, 0), make([]*Event, 0)}
+				cities[name.Sanitized] = city
+			}
+			switch event.Type {
+			case "event":
+				if event.Old {
+					city.EventsOld = append(city.EventsOld, event)
+				} else {
+					city.Events = append(city.Events, event)
+				}
+			case "group":
+				city.Groups = append(city.Groups, event)
+			case "shop":
+				city.Shops = append(city.Shops, event)
+			}
+		}
+	}
+
+	data.Cities = make([]*City, 0, len(cities))
+	for _, city := range cities {
+		city.Events = AddMonthSeparators(city.Events)
+		city.EventsOld = AddMonthSeparatorsDescending(city.EventsOld)
+		data.Cities = append(data.Cities, city)
+	}
+	sort.Slice(data.Cities, func(i, j int) bool { return data.Cities[i].Name.Sanitized < data.Cities[j].Name.Sanitized })
+}
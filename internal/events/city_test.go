@@ -0,0 +1,71 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestNormalizeCity(t *testing.T) {
+	for _, tc := range []struct{ city, expected string }{
+		{"Schwetzingen", "Schwetzingen"},
+		{" Schwetzingen (Schlossgarten) ", "Schwetzingen"},
+		{"Heidelberg, Neuenheimer Feld", "Heidelberg"},
+		{"HD", "Heidelberg"},
+		{"Rohrbach (Pfalz)", "Rohrbach"}, // not the district of Heidelberg
+		{"Boxberg", "Boxberg"},
+		{"Heidelberg-Handschuhsheim", "Heidelberg-Handschuhsheim"}, // resolved by collectCities
+		{"", ""},
+	} {
+		if city := NormalizeCity(tc.city); city != tc.expected {
+			t.Errorf("NormalizeCity(%q): expected %q, got %q", tc.city, tc.expected, city)
+		}
+	}
+}
+
+func TestCollectCities(t *testing.T) {
+	at := func(eventType, name, city string) *Event {
+		event := testEntry(t, name, "17.05.2026", "")
+		event.Type = eventType
+		event.Location = CreateLocation(city, "")
+		return event
+	}
+	old := at("event", "Stadtlauf", "Heidelberg-Rohrbach")
+	old.Old = true
+	data := Data{
+		Events: []*Event{
+			at("event", "Schlosslauf", "Schwetzingen (Schlossgarten)"),
+			at("event", "Trail", "Wald-Michelbach"),
+			at("event", "Ohne Ort", ""),
+			at("event", "Neckarlauf", "Neckargemünd-Dilsberg"),
+			at("event", "Burglauf", "Neckargemünd"),
+			at("event", "Weinstraßenlauf", "Rohrbach (Pfalz)"),
+		},
+		EventsOld: []*Event{old},
+		Groups:    []*Event{at("group", "Lauftreff", "HD-Kirchheim")},
+		Shops:     []*Event{at("shop", "Laufladen", "Schwetzingen")},
+	}
+	data.collectCities()
+
+	expected := map[string][4]int{
+		"heidelberg":      {0, 1, 1, 0},
+		"neckargemuend":   {2, 0, 0, 0},
+		"rohrbach":        {1, 0, 0, 0},
+		"schwetzingen":    {1, 0, 0, 1},
+		"wald-michelbach": {1, 0, 0, 0},
+	}
+	if len(data.Cities) != len(expected) {
+		t.Fatalf("expected %d cities, got %d", len(expected), len(data.Cities))
+	}
+	for _, city := range data.Cities {
+		counts, found := expected[city.Name.Sanitized]
+		if !found {
+			t.Errorf("unexpected city %q", city.Name.Orig)
+			continue
+		}
+		if got := [4]int{city.NumEvents(), city.NumOldEvents(), len(city.Groups), len(city.Shops)}; got != counts {
+			t.Errorf("city %q: expected %v, got %v", city.Name.Orig, counts, got)
+		}
+	}
+	if place := old.Location.Place; place != "Heidelberg" || old.Location.PlaceSlug() != "ort/heidelberg.html" {
+		t.Errorf("unexpected place %q", place)
+	}
+}
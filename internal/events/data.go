@@ -26,74 +26,25 @@ type Data struct {
 	Tags           []*Tag
 	Series         []*Serie
 	SeriesOld      []*Serie
+	Cities         []*City // towns of all entries, by name (see NormalizeCity)
 	ParkrunEvents  []*ParkrunEvent
+	SlugRedirects  []utils.Redirect // redirects from former slugs (see SlugRegistry)
+	Changes        []ChangeRecord   // recent changes, oldest first (see Changelog)
 }
 
-type CheckUrl struct {
-	Url   string
-	Event *Event
-	Name  string
-}
-
-func (data *Data) CheckLinks() {
-	// collect urls to check from current events
-	urls := make([]CheckUrl, 0)
-	for _, event := range data.Events {
-		if event.IsSeparator() {
-			continue
-		}
-		urls = append(urls, CheckUrl{Url: event.MainLink.Url, Event: event, Name: "main"})
-		for _, link := range event.Links {
-			if link.IsExternal() {
-				urls = append(urls, CheckUrl{Url: link.Url, Event: event, Name: "link"})
-			}
-		}
-	}
-
-	// do actual checks
-	// Group URLs by domain to implement per-domain rate limiting
-	domainMap := make(map[string][]CheckUrl)
-	for _, url := range urls {
-		domain := utils.ExtractDomain(url.Url)
-		domainMap[domain] = append(domainMap[domain], url)
-	}
-
-	lc := utils.NewLinkChecker()
-	results := make(chan struct {
-		url CheckUrl
-		err error
-	}, len(urls))
-
-	// Limit concurrent requests per domain
-	const perDomainLimit = 2
-	for domain, urlList := range domainMap {
-		fmt.Printf("Checking %d links for domain %s\n", len(urlList), domain)
-		sem := make(chan struct{}, perDomainLimit)
-		for _, u := range urlList {
-			sem <- struct{}{}
-			go func(u CheckUrl, sem chan struct{}) {
-				defer func() { <-sem }()
-				err := lc.Check(u.Url)
-				results <- struct {
-					url CheckUrl
-					err error
-				}{u, err}
-			}(u, sem)
-		}
-	}
-
-	for i := 0; i < len(urls); i++ {
-		res := <-results
-		if res.err != nil {
-			fmt.Printf("Invalid %s link in event '%s': %s -> %v\n", res.url.Name, res.url.Event.Name.Orig, res.url.Url, res.err)
-		}
+func FetchData(config SheetsConfigData, today time.Time, regions []*Region) (Data, error) {
+	srv, err := NewSheetsSource(config)
+	if err != nil {
+		return Data{}, err
 	}
+	return FetchDataFrom(srv, today, regions)
 }
 
-func FetchData(config SheetsConfigData, today time.Time) (Data, error) {
+// FetchDataFrom loads the events data from the tables of src; entries are tagged with the regions containing them.
+func FetchDataFrom(srv TableSource, today time.Time, regions []*Region) (Data, error) {
 	var data Data
 
-	sheetsData, err := LoadSheets(config, today)
+	sheetsData, err := LoadTables(srv, today, regions)
 	if err != nil {
 		return data, err
 	}
@@ -113,13 +64,12 @@ func FetchData(config SheetsConfigData, today time.Time) (Data, error) {
 	FindSiblings(data.Events, today)
 	data.Events, data.EventsOld = SplitEvents(data.Events)
 	data.Events = AddMonthSeparators(data.Events)
-	FindUpcomingNearEvents(data.Events, data.Events, 5.0, 3)
-	FindUpcomingNearEvents(data.EventsOld, data.Events, 5.0, 3)
 	data.EventsOld = Reverse(data.EventsOld)
 	data.EventsOld = AddMonthSeparatorsDescending(data.EventsOld)
 	ChangeRegistrationLinks(data.EventsOld)
 	data.collectTags()
 	data.collectSeries()
+	data.collectCities()
 
 	// Collect old events by year
 	maxYear := 0
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
 	"github.com/svengiegerich/heidelberg-run/internal/utils"
 	"github.com/google/uuid"
 )
@@ -17,6 +18,7 @@ type EventMeta struct {
 	BaseName utils.Name
 	SeoTitle string
 	Siblings []*Event
+	Slug     string // sanitized name part of the slug (SLUG column), overrides the name
 }
 
 type Event struct {
@@ -38,13 +40,14 @@ type Event struct {
 	RawSeries       []string
 	Series          []*Serie
 	Links           []*utils.Link
+	Deadline        time.Time // registration deadline (optional)
 	Calendar        string
 	CalendarDataICS string
 	CalendarGoogle  string
 	New             bool
 	Prev            *Event
 	Next            *Event
-	UpcomingNear    []*Event
+	UpcomingNear    []NearEvent
 	Meta            EventMeta
 }
 
@@ -66,6 +69,10 @@ func (event Event) GetUUID() (uuid.UUID, error) {
 }
 
 func (event Event) GenerateDescription() string {
+	return event.GenerateDescriptionIn(i18n.Default)
+}
+
+func (event Event) GenerateDescriptionIn(locale i18n.Locale) string {
 	min := 110
 	max := 160
 
@@ -73,25 +80,25 @@ func (event Event) GenerateDescription() string {
 
 	location := ""
 	if event.Location.NameNoFlag() != "" {
-		location = fmt.Sprintf(" in '%s'", event.Location.NameNoFlag())
+		location = locale.T("description.location", event.Location.NameNoFlag())
 	}
 
 	time := ""
 	if event.Time.Original != "" {
 		if event.Time.Original == "Verschiedene Termine" {
-			time = ", verschiedene Termine"
+			time = locale.T("description.various-dates")
 		} else {
-			time = fmt.Sprintf(" am %s", event.Time.Original)
+			time = locale.T("description.date", event.Time.Localized(locale))
 		}
 	}
 
 	switch event.Type {
 	case "event":
-		description = fmt.Sprintf("Informationen zur Laufveranstaltung '%s' %s %s", event.Name.Orig, location, time)
+		description = locale.T("description.event", event.Name.Orig, location, time)
 	case "group":
-		description = fmt.Sprintf("Informationen zur Laufgruppe / zum Lauftreff '%s' %s %s", event.Name.Orig, location, time)
+		description = locale.T("description.group", event.Name.Orig, location, time)
 	case "shop":
-		description = fmt.Sprintf("Informationen zum Lauf-Shop '%s' %s", event.Name.Orig, location)
+		description = locale.T("description.shop", event.Name.Orig, location)
 	}
 
 	if len(description) >= min {
@@ -128,13 +135,13 @@ func NonSeparators(events []*Event) int {
 }
 
 func createSeparatorEvent(t time.Time) *Event {
-	label := fmt.Sprintf("%s %d", utils.MonthStr(t.Month()), t.Year())
+	label := i18n.Default.FormatMonth(t)
 
 	return &Event{
 		"",
 		utils.NewName(label),
 		utils.NewName(""),
-		utils.TimeRange{},
+		utils.TimeRange{From: t, To: t},
 		false,
 		"",
 		false,
@@ -149,6 +156,7 @@ func createSeparatorEvent(t time.Time) *Event {
 		nil,
 		nil,
 		nil,
+		time.Time{},
 		"",
 		"",
 		"",
@@ -163,6 +171,9 @@ func createSeparatorEvent(t time.Time) *Event {
 func (event *Event) slug(ext string) string {
 	t := event.Type
 	sanitized := event.Name.Sanitized
+	if event.Meta.Slug != "" {
+		sanitized = event.Meta.Slug
+	}
 
 	if !event.Time.IsZero() {
 		return fmt.Sprintf("%s/%d-%s.%s", t, event.Time.Year(), sanitized, ext)
@@ -211,37 +222,45 @@ func (event *Event) CalendarSlug() string {
 }
 
 func (event *Event) LinkTitle() string {
+	return event.LinkTitleIn(i18n.Default)
+}
+
+func (event *Event) LinkTitleIn(locale i18n.Locale) string {
 	switch event.Type {
 	case "event":
 		if event.MainLink.IsEmail() {
-			return "Mail an Veranstalter"
+			return locale.T("linktitle.event-mail")
 		}
-		return "Zur Veranstaltung"
+		return locale.T("linktitle.event")
 	case "group":
 		if event.MainLink.IsEmail() {
-			return "Mail an Organisator"
+			return locale.T("linktitle.group-mail")
 		}
-		return "Zum Lauftreff"
+		return locale.T("linktitle.group")
 	case "shop":
-		return "Zum Lauf-Shop"
+		return locale.T("linktitle.shop")
 	default:
-		return "Zur Veranstaltung"
+		return locale.T("linktitle.event")
 	}
 }
 
 func (event *Event) NiceType() string {
+	return event.NiceTypeIn(i18n.Default)
+}
+
+func (event *Event) NiceTypeIn(locale i18n.Locale) string {
 	if event.Old {
-		return "vergangene Veranstaltung"
+		return locale.T("nicetype.event-old")
 	}
 	switch event.Type {
 	case "event":
-		return "Veranstaltung"
+		return locale.T("nicetype.event")
 	case "group":
-		return "Lauftreff"
+		return locale.T("nicetype.group")
 	case "shop":
-		return "Lauf-Shop"
+		return locale.T("nicetype.shop")
 	default:
-		return "Veranstaltung"
+		return locale.T("nicetype.event")
 	}
 }
 
@@ -468,24 +487,3 @@ func FindSiblings(eventList []*Event, today time.Time) {
 		current.Meta.Current = true
 	}
 }
-
-func FindUpcomingNearEvents(eventList []*Event, upcomingEvents []*Event, maxDistanceKM float64, count int) {
-	for _, event := range eventList {
-		if !event.Location.HasGeo() {
-			continue
-		}
-		event.UpcomingNear = make([]*Event, 0, count)
-		for _, candidate := range upcomingEvents {
-			if candidate == event || candidate.Cancelled || !candidate.Location.HasGeo() {
-				continue
-			}
-			if distanceKM, _ := utils.DistanceBearing(event.Location.Lat, event.Location.Lon, candidate.Location.Lat, candidate.Location.Lon); distanceKM > maxDistanceKM {
-				continue
-			}
-			event.UpcomingNear = append(event.UpcomingNear, candidate)
-			if len(event.UpcomingNear) >= count {
-				break
-			}
-		}
-	}
-}
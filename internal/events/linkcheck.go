@@ -0,0 +1,190 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// CheckUrl is a single use of a link on the site.
+type CheckUrl struct {
+	Url  string `json:"url"`
+	Type string `json:"type"` // event, group, shop, serie
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	Kind string `json:"kind"` // main, link
+}
+
+// LinkResult is a failing link with its check history.
+type LinkResult struct {
+	CheckUrl
+	Error    string `json:"error"`
+	Failures int    `json:"failures"` // consecutive failed runs
+	Flagged  bool   `json:"flagged"`  // failed at least threshold runs in a row
+}
+
+type LinkReport struct {
+	Generated time.Time    `json:"generated"`
+	Threshold int          `json:"threshold"`
+	Checked   int          `json:"checked"`
+	Failed    int          `json:"failed"`
+	Flagged   int          `json:"flagged"`
+	Results   []LinkResult `json:"results"` // failing links, flagged ones first
+}
+
+func (data *Data) collectUrls() []CheckUrl {
+	urls := make([]CheckUrl, 0)
+	addEvent := func(event *Event) {
+		if event.IsSeparator() {
+			return
+		}
+		if event.MainLink != nil {
+			urls = append(urls, CheckUrl{event.MainLink.Url, event.Type, event.Name.Orig, event.Slug(), "main"})
+		}
+		for _, link := range event.Links {
+			if link.IsExternal() {
+				urls = append(urls, CheckUrl{link.Url, event.Type, event.Name.Orig, event.Slug(), "link"})
+			}
+		}
+	}
+	for _, eventList := range [][]*Event{data.Events, data.Groups, data.Shops} {
+		for _, event := range eventList {
+			addEvent(event)
+		}
+	}
+	for _, serie := range data.Series {
+		for _, link := range serie.Links {
+			if link.IsExternal() {
+				urls = append(urls, CheckUrl{link.Url, "serie", serie.Name.Orig, serie.Slug(), "link"})
+			}
+		}
+	}
+	return urls
+}
+
+// CheckLinks checks the links of the upcoming events, the groups, the shops and the series. Each link is recorded in
+// history; a failing link is flagged once it failed in threshold consecutive runs.
+func (data *Data) CheckLinks(lc *utils.LinkChecker, history *utils.LinkHistory, threshold int, now time.Time) LinkReport {
+	urls := data.collectUrls()
+
+	// check each url once; group by domain to implement per-domain rate limiting
+	domainMap := make(map[string][]string)
+	seen := make(map[string]struct{})
+	for _, url := range urls {
+		if _, found := seen[url.Url]; found {
+			continue
+		}
+		seen[url.Url] = struct{}{}
+		domain := utils.ExtractDomain(url.Url)
+		domainMap[domain] = append(domainMap[domain], url.Url)
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	errs := make(map[string]error)
+
+	// Limit concurrent requests per domain
+	const perDomainLimit = 2
+	for _, urlList := range domainMap {
+		sem := make(chan struct{}, perDomainLimit)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var domainWg sync.WaitGroup
+			for _, u := range urlList {
+				sem <- struct{}{}
+				domainWg.Add(1)
+				go func() {
+					defer func() {
+						<-sem
+						domainWg.Done()
+					}()
+					err := lc.Check(u)
+					mutex.Lock()
+					errs[u] = err
+					mutex.Unlock()
+				}()
+			}
+			domainWg.Wait()
+		}()
+	}
+	wg.Wait()
+
+	states := make(map[string]utils.LinkState)
+	for url, err := range errs {
+		states[url] = history.Record(url, err, now)
+	}
+	history.Prune(now)
+
+	checked, failed := lc.Stats()
+	report := LinkReport{now, threshold, checked, failed, 0, make([]LinkResult, 0)}
+	for _, url := range urls {
+		err := errs[url.Url]
+		if err == nil {
+			continue
+		}
+		state := states[url.Url]
+		flagged := state.Failures >= threshold
+		if flagged {
+			report.Flagged += 1
+		}
+		report.Results = append(report.Results, LinkResult{url, err.Error(), state.Failures, flagged})
+	}
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		if a.Flagged != b.Flagged {
+			return a.Flagged
+		}
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.Name < b.Name
+	})
+	return report
+}
+
+var linkReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="de">
+<head>
+<meta charset="utf-8">
+<title>Link-Report {{.Generated.Format "2006-01-02 15:04"}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+tr.flagged { background: #fdd; }
+</style>
+</head>
+<body>
+<h1>Link-Report {{.Generated.Format "2006-01-02 15:04"}}</h1>
+<p>{{.Checked}} Links geprüft, {{.Failed}} fehlgeschlagen, {{.Flagged}} markiert (mindestens {{.Threshold}} Fehlschläge in Folge).</p>
+<table>
+<tr><th>Fehlschläge</th><th>Typ</th><th>Eintrag</th><th>Link</th><th>Fehler</th></tr>
+{{range .Results}}<tr{{if .Flagged}} class="flagged"{{end}}>
+<td>{{.Failures}}</td><td>{{.Type}} ({{.Kind}})</td><td>{{.Name}}<br><small>{{.Slug}}</small></td><td><a href="{{.Url}}">{{.Url}}</a></td><td>{{.Error}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// Write writes the report as 'report.json' and 'report.html' to dir.
+func (report LinkReport) Write(dir string) error {
+	if err := utils.WriteJSON(filepath.Join(dir, "report.json"), report); err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err := linkReportTemplate.Execute(&buffer, report); err != nil {
+		return fmt.Errorf("render link report: %w", err)
+	}
+	if _, err := utils.WriteFileIfChanged(filepath.Join(dir, "report.html"), buffer.Bytes()); err != nil {
+		return fmt.Errorf("write link report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestCheckLinks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	event := testEntry(t, "Trail Lauf", "17.05.2026", server.URL+"/ok")
+	event.Links = []*utils.Link{utils.CreateLink("Ergebnisse", server.URL+"/results"), utils.CreateLink("Mail", "mailto:info@example.com")}
+	group := &Event{Type: "group", Name: utils.NewName("Lauftreff"), MainLink: utils.CreateUnnamedLink(server.URL + "/group")}
+	serie := &Serie{Name: utils.NewName("Cup"), Links: []*utils.Link{utils.CreateLink("Cup", server.URL+"/ok")}}
+	data := Data{Events: []*Event{event}, Groups: []*Event{group}, Series: []*Serie{serie}}
+
+	history := &utils.LinkHistory{Links: make(map[string]utils.LinkState)}
+	var report LinkReport
+	for run := 0; run < 2; run++ {
+		report = data.CheckLinks(utils.NewLinkChecker(), history, 2, time.Date(2026, time.May, 1+run, 0, 0, 0, 0, time.UTC))
+		if run == 0 && report.Flagged != 0 {
+			t.Errorf("expected no flagged links after the first run, got %d", report.Flagged)
+		}
+	}
+	if report.Checked != 3 || report.Failed != 2 || report.Flagged != 2 {
+		t.Errorf("expected 3 checked, 2 failed, 2 flagged, got %d, %d, %d", report.Checked, report.Failed, report.Flagged)
+	}
+	for _, result := range report.Results {
+		if !result.Flagged || result.Failures != 2 {
+			t.Errorf("unexpected result %v", result)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := report.Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"report.json", "report.html"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
 	"github.com/svengiegerich/heidelberg-run/internal/utils"
 	"github.com/flopp/go-coordsparser"
 )
@@ -16,8 +17,15 @@ type Location struct {
 	Lon       float64
 	Distance  string
 	Direction string
+	Place     string // normalized town (see NormalizeCity), used for the city pages
 }
 
+// Heidelberg
+const (
+	centerLat = 49.3988
+	centerLon = 8.6724
+)
+
 var reFr = regexp.MustCompile(`\s*^(.*)\s*,\s*FR\s*(🇫🇷)?\s*$`)
 var reCh = regexp.MustCompile(`\s*^(.*)\s*,\s*CH\s*(🇨🇭)?\s*$`)
 
@@ -38,15 +46,12 @@ func CreateLocation(locationS, coordinatesS string) Location {
 	if err == nil {
 		coordinates = fmt.Sprintf("%.6f,%.6f", lat, lon)
 
-		// Heidelberg
-		lat0 := 49.3988
-		lon0 := 8.6724
-		d, b := utils.DistanceBearing(lat0, lon0, lat, lon)
+		d, b := utils.DistanceBearing(centerLat, centerLon, lat, lon)
 		distance = fmt.Sprintf("%.1fkm", d)
 		direction = utils.ApproxDirection(b)
 	}
 
-	return Location{locationS, country, coordinates, lat, lon, distance, direction}
+	return Location{locationS, country, coordinates, lat, lon, distance, direction, NormalizeCity(locationS)}
 }
 
 func (loc Location) Name() string {
@@ -81,12 +86,42 @@ func (loc Location) HasGeo() bool {
 	return loc.Geo != ""
 }
 
+// DistanceKM returns the distance to Heidelberg in kilometers (0 if the location has no coordinates).
+func (loc Location) DistanceKM() float64 {
+	if !loc.HasGeo() {
+		return 0
+	}
+	d, _ := utils.DistanceBearing(centerLat, centerLon, loc.Lat, loc.Lon)
+	return d
+}
+
 func (loc Location) Dir() string {
-	return fmt.Sprintf(`%s %s von Heidelberg`, loc.Distance, loc.Direction)
+	return loc.DirIn(i18n.Default)
 }
 
 func (loc Location) DirLong() string {
-	return fmt.Sprintf(`%s %s von Heidelberg Zentrum`, loc.Distance, loc.Direction)
+	return loc.DirLongIn(i18n.Default)
+}
+
+func (loc Location) direction(locale i18n.Locale) string {
+	if locale.IsDefault() {
+		return loc.Direction
+	}
+	_, b := utils.DistanceBearing(centerLat, centerLon, loc.Lat, loc.Lon)
+	return locale.Direction(utils.DirectionCode(b))
+}
+
+func (loc Location) DirIn(locale i18n.Locale) string {
+	return locale.T("location.dir", loc.Distance, loc.direction(locale))
+}
+
+func (loc Location) DirLongIn(locale i18n.Locale) string {
+	return locale.T("location.dir-long", loc.Distance, loc.direction(locale))
+}
+
+// PlaceSlug returns the slug of the page of the location's town.
+func (loc Location) PlaceSlug() string {
+	return CitySlug(utils.SanitizeName(loc.Place))
 }
 
 func (loc Location) GoogleMaps() string {
@@ -98,7 +133,6 @@ func (loc Location) Tags() []string {
 	if loc.Country != "" {
 		tags = append(tags, utils.SanitizeName(loc.Country))
 	}
-	// tags = append(tags, utils.SplitAndSanitize(loc.City)...)
 
 	return tags
 }
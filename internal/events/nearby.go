@@ -0,0 +1,157 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// kmPerDegree is the length of a degree of latitude.
+const kmPerDegree = 111.2
+
+// NearbyConfig configures the "nearby" suggestions of event pages. Candidates within RadiusKM are ranked by their
+// distance plus DayKM for each day between the dates; the Count best are shown.
+type NearbyConfig struct {
+	RadiusKM float64 `json:"radius_km"`
+	Count    int     `json:"count"`
+	DayKM    float64 `json:"day_km"`
+}
+
+// DefaultNearbyConfig ranks an event one month later like one 3km further away.
+var DefaultNearbyConfig = NearbyConfig{5.0, 3, 0.1}
+
+// LoadNearbyConfig reads the "nearby" object of the config file; missing values are taken from DefaultNearbyConfig.
+func LoadNearbyConfig(path string) (NearbyConfig, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return NearbyConfig{}, fmt.Errorf("load nearby config file '%s': %w", path, err)
+	}
+	var config struct {
+		Nearby NearbyConfig `json:"nearby"`
+	}
+	config.Nearby = DefaultNearbyConfig
+	if err := json.Unmarshal(buf, &config); err != nil {
+		return NearbyConfig{}, fmt.Errorf("unmarshall nearby config data: %w", err)
+	}
+	nearby := config.Nearby
+	if nearby.RadiusKM <= 0 || nearby.Count <= 0 || nearby.DayKM < 0 {
+		return NearbyConfig{}, fmt.Errorf("bad nearby config %+v", nearby)
+	}
+	return nearby, nil
+}
+
+// NearEvent is an entry near another one.
+type NearEvent struct {
+	*Event
+	DistanceKM float64
+}
+
+func (n NearEvent) Distance() string {
+	return fmt.Sprintf("%.1fkm", n.DistanceKM)
+}
+
+type gridCell struct {
+	lat, lon int
+}
+
+// SpatialIndex finds the entries near a position; it sorts the entries into a grid of cells of cellKM height (and
+// the same width in degrees), so a query only looks at the few cells overlapping the search radius.
+type SpatialIndex struct {
+	cellDeg float64
+	cells   map[gridCell][]*Event
+}
+
+// NewSpatialIndex indexes the entries with coordinates; cellKM should be about the typical search radius.
+func NewSpatialIndex(entries []*Event, cellKM float64) *SpatialIndex {
+	index := &SpatialIndex{cellKM / kmPerDegree, make(map[gridCell][]*Event)}
+	for _, entry := range entries {
+		if entry.IsSeparator() || !entry.Location.HasGeo() {
+			continue
+		}
+		cell := index.cell(entry.Location.Lat, entry.Location.Lon)
+		index.cells[cell] = append(index.cells[cell], entry)
+	}
+	return index
+}
+
+func (index *SpatialIndex) cell(lat, lon float64) gridCell {
+	return gridCell{int(math.Floor(lat / index.cellDeg)), int(math.Floor(lon / index.cellDeg))}
+}
+
+// Within returns the indexed entries within radiusKM of the position, closest first.
+func (index *SpatialIndex) Within(lat, lon, radiusKM float64) []NearEvent {
+	dLat := radiusKM / kmPerDegree
+	dLon := dLat / max(math.Cos(lat*math.Pi/180), 0.01)
+	minCell := index.cell(lat-dLat, lon-dLon)
+	maxCell := index.cell(lat+dLat, lon+dLon)
+
+	found := make([]NearEvent, 0)
+	for cellLat := minCell.lat; cellLat <= maxCell.lat; cellLat++ {
+		for cellLon := minCell.lon; cellLon <= maxCell.lon; cellLon++ {
+			for _, entry := range index.cells[gridCell{cellLat, cellLon}] {
+				distanceKM, _ := utils.DistanceBearing(lat, lon, entry.Location.Lat, entry.Location.Lon)
+				if distanceKM <= radiusKM {
+					found = append(found, NearEvent{entry, distanceKM})
+				}
+			}
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].DistanceKM < found[j].DistanceKM
+	})
+	return found
+}
+
+func daysBetween(a, b time.Time) float64 {
+	return math.Abs(b.Sub(a).Hours()) / 24
+}
+
+// FindNearby sets the UpcomingNear events of all upcoming and past events: the upcoming events (not cancelled)
+// within the radius with the best scores (distance plus the weighted days between the event, or today for past
+// events, and the candidate).
+func (data *Data) FindNearby(config NearbyConfig, today time.Time) {
+	candidates := make([]*Event, 0)
+	for _, event := range data.Events {
+		if !event.IsSeparator() && !event.Cancelled {
+			candidates = append(candidates, event)
+		}
+	}
+	index := NewSpatialIndex(candidates, config.RadiusKM)
+
+	for _, eventList := range [][]*Event{data.Events, data.EventsOld} {
+		for _, event := range eventList {
+			if event.IsSeparator() || !event.Location.HasGeo() {
+				continue
+			}
+			reference := event.Time.From
+			if reference.IsZero() || reference.Before(today) {
+				reference = today
+			}
+			score := func(n NearEvent) float64 {
+				if n.Time.IsZero() {
+					return n.DistanceKM
+				}
+				return n.DistanceKM + config.DayKM*daysBetween(reference, n.Time.From)
+			}
+
+			near := make([]NearEvent, 0)
+			for _, candidate := range index.Within(event.Location.Lat, event.Location.Lon, config.RadiusKM) {
+				if candidate.Event != event {
+					near = append(near, candidate)
+				}
+			}
+			sort.SliceStable(near, func(i, j int) bool {
+				return score(near[i]) < score(near[j])
+			})
+			if len(near) > config.Count {
+				near = near[:config.Count]
+			}
+			event.UpcomingNear = near
+		}
+	}
+}
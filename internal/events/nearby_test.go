@@ -0,0 +1,105 @@
+package events
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestSpatialIndex(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	entries := make([]*Event, 0)
+	for i := 0; i < 500; i++ {
+		lat := 49.0 + random.Float64()
+		lon := 8.0 + random.Float64()
+		entries = append(entries, &Event{Type: "event", Location: Location{Geo: "x", Lat: lat, Lon: lon}})
+	}
+	index := NewSpatialIndex(entries, 5)
+
+	for i := 0; i < 50; i++ {
+		lat := 49.0 + random.Float64()
+		lon := 8.0 + random.Float64()
+		found := index.Within(lat, lon, 8)
+
+		expected := 0
+		for _, entry := range entries {
+			if d, _ := utils.DistanceBearing(lat, lon, entry.Location.Lat, entry.Location.Lon); d <= 8 {
+				expected += 1
+			}
+		}
+		if len(found) != expected {
+			t.Fatalf("expected %d entries, got %d", expected, len(found))
+		}
+		for j := 1; j < len(found); j++ {
+			if found[j-1].DistanceKM > found[j].DistanceKM {
+				t.Fatalf("entries not sorted by distance")
+			}
+		}
+	}
+}
+
+func TestFindNearby(t *testing.T) {
+	at := func(name, date string, lat, lon float64) *Event {
+		event := testEntry(t, name, date, "")
+		event.Location = Location{Geo: "x", Lat: lat, Lon: lon}
+		return event
+	}
+	event := at("Stadtlauf", "14.06.2026", 49.40, 8.70)
+	sameDayFar := at("Brückenlauf", "14.06.2026", 49.43, 8.70) // ~3.3km
+	laterClose := at("Nachtlauf", "12.12.2026", 49.401, 8.70)  // ~0.1km, half a year later
+	soonClose := at("Sommerlauf", "21.06.2026", 49.405, 8.70)  // ~0.6km
+	cancelled := at("Abgesagt", "15.06.2026", 49.40, 8.701)
+	cancelled.Cancelled = true
+	farAway := at("Bergzeitfahren", "14.06.2026", 49.50, 8.70) // ~11km
+	old := at("Maienlauf", "10.05.2026", 49.40, 8.70)
+	data := Data{Events: []*Event{event, {}, sameDayFar, laterClose, soonClose, cancelled, farAway}, EventsOld: []*Event{old}}
+
+	data.FindNearby(NearbyConfig{5, 2, 0.1}, time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC))
+	names := func(near []NearEvent) []string {
+		result := make([]string, 0)
+		for _, n := range near {
+			result = append(result, n.Name.Orig)
+		}
+		return result
+	}
+	if got := names(event.UpcomingNear); len(got) != 2 || got[0] != "Sommerlauf" || got[1] != "Brückenlauf" {
+		t.Errorf("unexpected nearby events %v", got)
+	}
+	if got := names(old.UpcomingNear); len(got) != 2 || got[0] != "Stadtlauf" || got[1] != "Sommerlauf" {
+		t.Errorf("unexpected nearby events of old event %v", got)
+	}
+	if d := event.UpcomingNear[1].Distance(); d != "3.3km" {
+		t.Errorf("unexpected distance %s", d)
+	}
+
+	// pure distance ranking
+	data.FindNearby(NearbyConfig{5, 3, 0}, time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC))
+	if got := names(event.UpcomingNear); len(got) != 3 || got[0] != "Nachtlauf" || got[1] != "Sommerlauf" || got[2] != "Brückenlauf" {
+		t.Errorf("unexpected nearby events %v", got)
+	}
+}
+
+func TestLoadNearbyConfig(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(fileName, []byte(`{"sheet_id": "x", "nearby": {"radius_km": 10}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadNearbyConfig(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config != (NearbyConfig{10, DefaultNearbyConfig.Count, DefaultNearbyConfig.DayKM}) {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	if err := os.WriteFile(fileName, []byte(`{"nearby": {"count": 0}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadNearbyConfig(fileName); err == nil {
+		t.Errorf("expected error for bad config")
+	}
+}
@@ -0,0 +1,237 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// ring is a closed line of [lon, lat] positions.
+type ring [][2]float64
+
+// polygon is an outer ring followed by its holes.
+type polygon []ring
+
+// Region is an area given by polygons; entries located inside get the region's tag.
+type Region struct {
+	Tag         string // sanitized tag name
+	Name        string
+	Description string
+	polygons    []polygon
+	minLat      float64
+	minLon      float64
+	maxLat      float64
+	maxLon      float64
+}
+
+// contains reports whether the point is inside the ring (ray casting; points on the border may go either way).
+func (r ring) contains(lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		lon1, lat1 := r[i][0], r[i][1]
+		lon2, lat2 := r[j][0], r[j][1]
+		if (lat1 > lat) != (lat2 > lat) && lon < lon1+(lat-lat1)*(lon2-lon1)/(lat2-lat1) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (p polygon) contains(lat, lon float64) bool {
+	if len(p) == 0 || !p[0].contains(lat, lon) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if hole.contains(lat, lon) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether the position is inside one of the region's polygons.
+func (region *Region) Contains(lat, lon float64) bool {
+	if lat < region.minLat || lat > region.maxLat || lon < region.minLon || lon > region.maxLon {
+		return false
+	}
+	for _, p := range region.polygons {
+		if p.contains(lat, lon) {
+			return true
+		}
+	}
+	return false
+}
+
+func (region *Region) add(p polygon) {
+	if len(region.polygons) == 0 {
+		lon, lat := p[0][0][0], p[0][0][1]
+		region.minLat, region.maxLat, region.minLon, region.maxLon = lat, lat, lon, lon
+	}
+	for _, pos := range p[0] {
+		lon, lat := pos[0], pos[1]
+		region.minLat = min(region.minLat, lat)
+		region.maxLat = max(region.maxLat, lat)
+		region.minLon = min(region.minLon, lon)
+		region.maxLon = max(region.maxLon, lon)
+	}
+	region.polygons = append(region.polygons, p)
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Properties struct {
+			Tag         string `json:"tag"`
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		} `json:"properties"`
+		Geometry struct {
+			Type        string          `json:"type"`
+			Coordinates json.RawMessage `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"features"`
+}
+
+func parsePolygons(geometryType string, coordinates json.RawMessage) ([]polygon, error) {
+	var polygons []polygon
+	switch geometryType {
+	case "Polygon":
+		var p polygon
+		if err := json.Unmarshal(coordinates, &p); err != nil {
+			return nil, err
+		}
+		polygons = []polygon{p}
+	case "MultiPolygon":
+		if err := json.Unmarshal(coordinates, &polygons); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported geometry type '%s'", geometryType)
+	}
+	for _, p := range polygons {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("polygon without rings")
+		}
+		for _, r := range p {
+			if len(r) < 4 {
+				return nil, fmt.Errorf("ring with %d positions", len(r))
+			}
+		}
+	}
+	return polygons, nil
+}
+
+// LoadRegions reads the regions of all GeoJSON files (*.geojson) in dir: feature collections of polygons and
+// multi polygons with "tag", "name" and an optional "description" property. Features with the same tag form one
+// region. An empty dir means no regions; a missing dir is an error.
+func LoadRegions(dir string) ([]*Region, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if info, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("load regions: %w", err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("load regions: '%s' is not a directory", dir)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.geojson"))
+	if err != nil {
+		return nil, fmt.Errorf("list region files: %w", err)
+	}
+	sort.Strings(files)
+
+	regions := make([]*Region, 0)
+	byTag := make(map[string]*Region)
+	for _, file := range files {
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("load region file '%s': %w", file, err)
+		}
+		var collection geoJSONFeatureCollection
+		if err := json.Unmarshal(buf, &collection); err != nil {
+			return nil, fmt.Errorf("unmarshall region file '%s': %w", file, err)
+		}
+		if collection.Type != "FeatureCollection" {
+			return nil, fmt.Errorf("region file '%s': expecting a FeatureCollection, got '%s'", file, collection.Type)
+		}
+		for i, feature := range collection.Features {
+			tag := utils.SanitizeName(feature.Properties.Tag)
+			if tag == "" {
+				return nil, fmt.Errorf("region file '%s', feature %d: missing tag", file, i)
+			}
+			polygons, err := parsePolygons(feature.Geometry.Type, feature.Geometry.Coordinates)
+			if err != nil {
+				return nil, fmt.Errorf("region file '%s', feature %d: %w", file, i, err)
+			}
+			region, found := byTag[tag]
+			if !found {
+				name := strings.TrimSpace(feature.Properties.Name)
+				if name == "" {
+					name = tag
+				}
+				region = &Region{Tag: tag, Name: name, Description: strings.TrimSpace(feature.Properties.Description)}
+				byTag[tag] = region
+				regions = append(regions, region)
+			}
+			for _, p := range polygons {
+				region.add(p)
+			}
+		}
+	}
+	return regions, nil
+}
+
+// RegionTags returns the tags of the regions containing the location.
+func RegionTags(regions []*Region, loc Location) []string {
+	tags := make([]string, 0)
+	if !loc.HasGeo() {
+		return tags
+	}
+	for _, region := range regions {
+		if region.Contains(loc.Lat, loc.Lon) {
+			tags = append(tags, region.Tag)
+		}
+	}
+	return tags
+}
+
+// addRegionTags marks the tags of the regions and adds their names and descriptions to the tags table; names and
+// descriptions from the table take precedence. Regions without entries only get a tag if the table has one.
+func addRegionTags(tags []*Tag, regions []*Region, lists [][]*Event) []*Tag {
+	byName := make(map[string]*Tag)
+	for _, tag := range tags {
+		byName[tag.Name.Sanitized] = tag
+	}
+	used := make(map[string]bool)
+	for _, list := range lists {
+		for _, event := range list {
+			for _, t := range event.RawTags {
+				used[t] = true
+			}
+		}
+	}
+	for _, region := range regions {
+		tag, found := byName[region.Tag]
+		if !found {
+			if !used[region.Tag] {
+				continue
+			}
+			tag = CreateTag(region.Tag)
+			tag.Name.Orig = ""
+			byName[region.Tag] = tag
+			tags = append(tags, tag)
+		}
+		if tag.Name.Orig == "" {
+			tag.Name.Orig = region.Name
+		}
+		if tag.Description == "" {
+			tag.Description = region.Description
+		}
+		tag.Region = true
+	}
+	return tags
+}
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const testRegions = `{"type": "FeatureCollection", "features": [
+	{"properties": {"tag": "Ring", "name": "Ring"}, "geometry": {"type": "Polygon", "coordinates": [
+		[[8, 49], [9, 49], [9, 50], [8, 50], [8, 49]],
+		[[8.4, 49.4], [8.6, 49.4], [8.6, 49.6], [8.4, 49.6], [8.4, 49.4]]]}},
+	{"properties": {"tag": "Inseln", "description": "Zwei Inseln."}, "geometry": {"type": "MultiPolygon", "coordinates": [
+		[[[10, 49], [10.5, 49], [10, 49.5], [10, 49]]],
+		[[[11, 49], [11.5, 49], [11, 49.5], [11, 49]]]]}}
+]}`
+
+func TestLoadRegions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "test.geojson"), []byte(testRegions), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	regions, err := LoadRegions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regions) != 2 || regions[0].Tag != "ring" || regions[1].Tag != "inseln" || regions[1].Name != "inseln" {
+		t.Fatalf("unexpected regions %+v", regions)
+	}
+
+	for _, tc := range []struct {
+		lat, lon float64
+		expected []string
+	}{
+		{49.2, 8.2, []string{"ring"}},
+		{49.5, 8.5, []string{}}, // hole
+		{49.1, 10.1, []string{"inseln"}},
+		{49.1, 11.1, []string{"inseln"}},
+		{49.4, 10.4, []string{}}, // outside the triangle, inside its bounding box
+		{48.0, 8.0, []string{}},
+	} {
+		tags := RegionTags(regions, Location{Geo: "x", Lat: tc.lat, Lon: tc.lon})
+		if !slices.Equal(tags, tc.expected) {
+			t.Errorf("%f,%f: expected %v, got %v", tc.lat, tc.lon, tc.expected, tags)
+		}
+	}
+	if tags := RegionTags(regions, Location{Lat: 49.2, Lon: 8.2}); len(tags) != 0 {
+		t.Errorf("expected no tags without coordinates, got %v", tags)
+	}
+
+	if regions, err := LoadRegions(""); err != nil || len(regions) != 0 {
+		t.Errorf("expected no regions without a dir, got %v, %v", regions, err)
+	}
+	if _, err := LoadRegions(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("expected error for a missing dir")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bad.geojson"), []byte(`{"type": "FeatureCollection", "features": [{"properties": {"tag": "x"}, "geometry": {"type": "Point", "coordinates": [8, 49]}}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRegions(dir); err == nil {
+		t.Errorf("expected error for a point geometry")
+	}
+}
+
+// testTownRegions roughly covers Wald-Michelbach (odenwald) and the surroundings of Heidelberg without the city and
+// without Mannheim (rhein-neckar-kreis); the entries of testSnapshot are located in Heidelberg, Wald-Michelbach and
+// Mannheim.
+const testTownRegions = `{"type": "FeatureCollection", "features": [
+	{"properties": {"tag": "odenwald", "name": "Odenwald", "description": "Mittelgebirge."}, "geometry": {"type": "Polygon", "coordinates": [
+		[[8.75, 49.50], [8.95, 49.50], [8.95, 49.65], [8.75, 49.65], [8.75, 49.50]]]}},
+	{"properties": {"tag": "rhein-neckar-kreis", "name": "Rhein-Neckar-Kreis"}, "geometry": {"type": "Polygon", "coordinates": [
+		[[8.55, 49.25], [9.00, 49.25], [9.00, 49.50], [8.55, 49.50], [8.55, 49.25]],
+		[[8.60, 49.35], [8.75, 49.35], [8.75, 49.45], [8.60, 49.45], [8.60, 49.35]]]}},
+	{"properties": {"tag": "traillauf", "name": "Trail"}, "geometry": {"type": "Polygon", "coordinates": [
+		[[8.80, 49.55], [8.85, 49.55], [8.85, 49.60], [8.80, 49.60], [8.80, 49.55]]]}}
+]}`
+
+func TestRegionTags(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "regions.geojson"), []byte(testTownRegions), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	regions, err := LoadRegions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := FetchDataFrom(testSnapshot(), time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), regions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"Maienlauf":          {"volkslauf"},             // Heidelberg
+		"Lauftreff Altstadt": {},                        // Heidelberg
+		"Odenwald Trail":     {"odenwald", "traillauf"}, // Wald-Michelbach; "traillauf" only once
+		"Brückenlauf":        {},                        // Mannheim
+	}
+	for _, list := range [][]*Event{data.Events, data.EventsOld, data.Groups} {
+		for _, event := range list {
+			if event.IsSeparator() {
+				continue
+			}
+			tags := make([]string, 0)
+			for _, tag := range event.Tags {
+				tags = append(tags, tag.Name.Sanitized)
+			}
+			slices.Sort(tags)
+			if !slices.Equal(tags, expected[event.Name.Orig]) {
+				t.Errorf("%s: expected tags %v, got %v", event.Name.Orig, expected[event.Name.Orig], tags)
+			}
+		}
+	}
+
+	tags := make(map[string]*Tag)
+	for _, tag := range data.Tags {
+		tags[tag.Name.Sanitized] = tag
+	}
+	if tag := tags["odenwald"]; tag == nil || !tag.Region || tag.Name.Orig != "Odenwald" || tag.Description != "Mittelgebirge." {
+		t.Errorf("unexpected odenwald tag %+v", tag)
+	}
+	if tag, found := tags["rhein-neckar-kreis"]; found {
+		t.Errorf("unexpected tag of a region without entries %+v", tag)
+	}
+	// name and description of the tags sheet take precedence
+	if tag := tags["traillauf"]; tag == nil || !tag.Region || tag.Name.Orig != "Traillauf" || tag.Description != "Läufe abseits befestigter Wege" {
+		t.Errorf("unexpected traillauf tag %+v", tag)
+	}
+	if tag := tags["volkslauf"]; tag == nil || tag.Region {
+		t.Errorf("unexpected volkslauf tag %+v", tag)
+	}
+}
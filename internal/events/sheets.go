@@ -7,6 +7,7 @@ import (
 	"html/template"
 	"log"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -43,14 +44,65 @@ type SheetsData struct {
 	Series  []*Serie
 }
 
-func LoadSheets(config SheetsConfigData, today time.Time) (SheetsData, error) {
+// TableSource provides the raw tables of the events spreadsheet.
+type TableSource interface {
+	// SheetNames returns the names of all sheets.
+	SheetNames() ([]string, error)
+	// Table returns the rows of a sheet, including the header row.
+	Table(name string) ([][]interface{}, error)
+}
+
+// sheetsSource fetches the tables from Google Sheets.
+type sheetsSource struct {
+	config SheetsConfigData
+	srv    *sheets.Service
+}
+
+func NewSheetsSource(config SheetsConfigData) (TableSource, error) {
 	ctx := context.Background()
 	srv, err := sheets.NewService(ctx, option.WithAPIKey(config.ApiKey))
 	if err != nil {
-		return SheetsData{}, fmt.Errorf("creating sheets service: %w", err)
+		return nil, fmt.Errorf("creating sheets service: %w", err)
+	}
+	return sheetsSource{config, srv}, nil
+}
+
+func (s sheetsSource) SheetNames() ([]string, error) {
+	response, err := s.srv.Spreadsheets.Get(s.config.SheetId).Fields("sheets(properties(sheetId,title))").Do()
+	if err != nil {
+		return nil, err
+	}
+	if response.HTTPStatusCode != 200 {
+		return nil, fmt.Errorf("http status %v when trying to get sheets", response.HTTPStatusCode)
+	}
+	sheets := make([]string, 0)
+	for _, v := range response.Sheets {
+		prop := v.Properties
+		sheets = append(sheets, prop.Title)
+	}
+	return sheets, nil
+}
+
+func (s sheetsSource) Table(name string) ([][]interface{}, error) {
+	resp, err := s.srv.Spreadsheets.Values.Get(s.config.SheetId, fmt.Sprintf("%s!A1:Z", name)).Do()
+	if err != nil {
+		return nil, err
 	}
+	return resp.Values, nil
+}
 
-	sheets, err := getAllSheets(config, srv)
+func LoadSheets(config SheetsConfigData, today time.Time, regions []*Region) (SheetsData, error) {
+	srv, err := NewSheetsSource(config)
+	if err != nil {
+		return SheetsData{}, err
+	}
+	return LoadTables(srv, today, regions)
+}
+
+// LoadTables parses the events, groups, shops, parkrun events, tags and series tables of src; entries are tagged
+// with the regions (see LoadRegions) containing their coordinates.
+func LoadTables(srv TableSource, today time.Time, regions []*Region) (SheetsData, error) {
+	sheets, err := srv.SheetNames()
 	if err != nil {
 		return SheetsData{}, fmt.Errorf("fetching all sheets: %w", err)
 	}
@@ -60,27 +112,28 @@ func LoadSheets(config SheetsConfigData, today time.Time) (SheetsData, error) {
 		return SheetsData{}, err
 	}
 
-	events, err := loadEvents(config, srv, today, eventSheets)
+	events, err := loadEvents(srv, today, eventSheets, regions)
 	if err != nil {
 		return SheetsData{}, fmt.Errorf("fetching events: %w", err)
 	}
-	groups, err := fetchEvents(config, srv, today, "group", groupsSheet)
+	groups, err := fetchEvents(srv, today, "group", groupsSheet, regions)
 	if err != nil {
 		return SheetsData{}, fmt.Errorf("fetching groups: %w", err)
 	}
-	shops, err := fetchEvents(config, srv, today, "shop", shopsSheet)
+	shops, err := fetchEvents(srv, today, "shop", shopsSheet, regions)
 	if err != nil {
 		return SheetsData{}, fmt.Errorf("fetching shops: %w", err)
 	}
-	parkrun, err := fetchParkrunEvents(config, srv, today, parkrunSheet)
+	parkrun, err := fetchParkrunEvents(srv, today, parkrunSheet)
 	if err != nil {
 		return SheetsData{}, fmt.Errorf("fetching parkrun events: %w", err)
 	}
-	tags, err := fetchTags(config, srv, tagsSheet)
+	tags, err := fetchTags(srv, tagsSheet)
 	if err != nil {
 		return SheetsData{}, fmt.Errorf("fetching tags: %w", err)
 	}
-	series, err := fetchSeries(config, srv, seriesSheet)
+	tags = addRegionTags(tags, regions, [][]*Event{events, groups, shops})
+	series, err := fetchSeries(srv, seriesSheet)
 	if err != nil {
 		return SheetsData{}, fmt.Errorf("fetching series: %w", err)
 	}
@@ -137,10 +190,10 @@ func findSheetNames(sheets []string) (eventSheets []string, groupsSheet, shopsSh
 	return eventSheets, groupsSheet, shopsSheet, parkrunSheet, tagsSheet, seriesSheet, nil
 }
 
-func loadEvents(config SheetsConfigData, srv *sheets.Service, today time.Time, eventSheets []string) ([]*Event, error) {
+func loadEvents(srv TableSource, today time.Time, eventSheets []string, regions []*Region) ([]*Event, error) {
 	eventList := make([]*Event, 0)
 	for _, sheet := range eventSheets {
-		yearList, err := fetchEvents(config, srv, today, "event", sheet)
+		yearList, err := fetchEvents(srv, today, "event", sheet, regions)
 		if err != nil {
 			return nil, err
 		}
@@ -149,22 +202,6 @@ func loadEvents(config SheetsConfigData, srv *sheets.Service, today time.Time, e
 	return eventList, nil
 }
 
-func getAllSheets(config SheetsConfigData, srv *sheets.Service) ([]string, error) {
-	response, err := srv.Spreadsheets.Get(config.SheetId).Fields("sheets(properties(sheetId,title))").Do()
-	if err != nil {
-		return nil, err
-	}
-	if response.HTTPStatusCode != 200 {
-		return nil, fmt.Errorf("http status %v when trying to get sheets", response.HTTPStatusCode)
-	}
-	sheets := make([]string, 0)
-	for _, v := range response.Sheets {
-		prop := v.Properties
-		sheets = append(sheets, prop.Title)
-	}
-	return sheets, nil
-}
-
 type Columns struct {
 	index map[string]int
 }
@@ -200,17 +237,17 @@ func (cols *Columns) getVal(col string, row []interface{}) (string, error) {
 	return fmt.Sprintf("%v", row[colIndex]), nil
 }
 
-func fetchTable(config SheetsConfigData, srv *sheets.Service, table string) (Columns, [][]interface{}, error) {
-	resp, err := srv.Spreadsheets.Values.Get(config.SheetId, fmt.Sprintf("%s!A1:Z", table)).Do()
+func fetchTable(srv TableSource, table string) (Columns, [][]interface{}, error) {
+	values, err := srv.Table(table)
 	if err != nil {
 		return Columns{}, nil, fmt.Errorf("cannot fetch table '%s': %v", table, err)
 	}
-	if len(resp.Values) == 0 {
+	if len(values) == 0 {
 		return Columns{}, nil, fmt.Errorf("got 0 rows when fetching table '%s'", table)
 	}
 	cols := Columns{}
-	rows := make([][]interface{}, 0, len(resp.Values)-1)
-	for line, row := range resp.Values {
+	rows := make([][]interface{}, 0, len(values)-1)
+	for line, row := range values {
 		if line == 0 {
 			cols, err = initColumns(row)
 			if err != nil {
@@ -248,7 +285,9 @@ type EventData struct {
 	Location     string
 	Coordinates  string
 	Registration string
+	Deadline     string
 	Tags         string
+	Slug         string
 	Links        []string
 }
 
@@ -277,12 +316,15 @@ func getEventData(cols Columns, row []interface{}) (EventData, error) {
 			return EventData{}, err
 		}
 	}
+	// optional columns
+	data.Deadline, _ = cols.getVal("DEADLINE", row)
+	data.Slug, _ = cols.getVal("SLUG", row)
 	data.Links = getLinks(cols, row)
 	return data, nil
 }
 
-func fetchEvents(config SheetsConfigData, srv *sheets.Service, today time.Time, eventType string, table string) ([]*Event, error) {
-	cols, rows, err := fetchTable(config, srv, table)
+func fetchEvents(srv TableSource, today time.Time, eventType string, table string, regions []*Region) ([]*Event, error) {
+	cols, rows, err := fetchTable(srv, table)
 	if err != nil {
 		return nil, err
 	}
@@ -338,6 +380,11 @@ func fetchEvents(config SheetsConfigData, srv *sheets.Service, today time.Time,
 		}
 		location := CreateLocation(data.Location, data.Coordinates)
 		tags = append(tags, location.Tags()...)
+		for _, t := range RegionTags(regions, location) {
+			if !slices.Contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
 		timeRange, err := utils.CreateTimeRange(data.Date)
 		if err != nil {
 			log.Printf("event '%s': %v", name, err)
@@ -347,6 +394,12 @@ func fetchEvents(config SheetsConfigData, srv *sheets.Service, today time.Time,
 		if err != nil {
 			return nil, fmt.Errorf("parsing links of event '%s': %w", name, err)
 		}
+		var deadline time.Time
+		if data.Deadline != "" {
+			if deadline, err = utils.ParseDate(data.Deadline); err != nil {
+				log.Printf("event '%s': bad registration deadline: %v", name, err)
+			}
+		}
 
 		eventsList = append(eventsList, &Event{
 			eventType,
@@ -367,6 +420,7 @@ func fetchEvents(config SheetsConfigData, srv *sheets.Service, today time.Time,
 			series,
 			nil,
 			links,
+			deadline,
 			"",
 			"",
 			"",
@@ -379,6 +433,7 @@ func fetchEvents(config SheetsConfigData, srv *sheets.Service, today time.Time,
 				utils.NewName(data.Name2),
 				data.Seo,
 				nil,
+				utils.SanitizeName(data.Slug),
 			},
 		})
 	}
@@ -426,8 +481,8 @@ func getParkrunEventData(cols Columns, row []interface{}) (ParkrunEventData, err
 	return data, nil
 }
 
-func fetchParkrunEvents(config SheetsConfigData, srv *sheets.Service, today time.Time, table string) ([]*ParkrunEvent, error) {
-	cols, rows, err := fetchTable(config, srv, table)
+func fetchParkrunEvents(srv TableSource, today time.Time, table string) ([]*ParkrunEvent, error) {
+	cols, rows, err := fetchTable(srv, table)
 	if err != nil {
 		return nil, err
 	}
@@ -502,8 +557,8 @@ func getTagData(cols Columns, row []interface{}) (TagData, error) {
 	return data, nil
 }
 
-func fetchTags(config SheetsConfigData, srv *sheets.Service, table string) ([]*Tag, error) {
-	cols, rows, err := fetchTable(config, srv, table)
+func fetchTags(srv TableSource, table string) ([]*Tag, error) {
+	cols, rows, err := fetchTable(srv, table)
 	if err != nil {
 		return nil, err
 	}
@@ -554,8 +609,8 @@ func getSerieData(cols Columns, row []interface{}) (SerieData, error) {
 	return data, nil
 }
 
-func fetchSeries(config SheetsConfigData, srv *sheets.Service, table string) ([]*Serie, error) {
-	cols, rows, err := fetchTable(config, srv, table)
+func fetchSeries(srv TableSource, table string) ([]*Serie, error) {
+	cols, rows, err := fetchTable(srv, table)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,186 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// SlugRegistry remembers every slug an entry ever had, so that renamed entries keep their old URLs as redirects.
+// A record is keyed by the entry's identity (see Event.Identity) when it was created, plus its first slug if that
+// key was taken; the key never changes afterwards.
+type SlugRegistry struct {
+	Entries map[string][]string `json:"entries"` // key -> slugs, the current slug last
+}
+
+// LoadSlugRegistry reads the registry file; a missing file yields an empty registry.
+func LoadSlugRegistry(path string) (*SlugRegistry, error) {
+	registry := &SlugRegistry{make(map[string][]string)}
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load slug registry file '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(buf, registry); err != nil {
+		return nil, fmt.Errorf("unmarshall slug registry data: %w", err)
+	}
+	if registry.Entries == nil {
+		registry.Entries = make(map[string][]string)
+	}
+	return registry, nil
+}
+
+func (r *SlugRegistry) Save(path string) error {
+	return utils.WriteJSON(path, r)
+}
+
+// Identity returns a key that survives renames: type, year and main URL (without scheme, 'www.' and trailing slash).
+func (event *Event) Identity() string {
+	url := ""
+	if event.MainLink != nil {
+		url = strings.ToLower(event.MainLink.Url)
+	}
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	url = strings.TrimSuffix(strings.TrimPrefix(url, "www."), "/")
+	if event.Time.IsZero() {
+		return fmt.Sprintf("%s|%s", event.Type, url)
+	}
+	return fmt.Sprintf("%s|%d|%s", event.Type, event.Time.Year(), url)
+}
+
+func (event *Event) describe() string {
+	if event.Time.Original != "" {
+		return fmt.Sprintf("%s '%s' (%s)", event.Type, event.Name.Orig, event.Time.Original)
+	}
+	return fmt.Sprintf("%s '%s'", event.Type, event.Name.Orig)
+}
+
+// Update records the current slugs of all entries of data and sets data.SlugRedirects to the redirects from their
+// former slugs. Two entries with the same slug are an error (one page would overwrite the other).
+func (r *SlugRegistry) Update(data *Data) error {
+	entries := make([]*Event, 0)
+	for _, eventList := range [][]*Event{data.Events, data.EventsOld, data.EventsObsolete, data.Groups, data.GroupsObsolete, data.Shops, data.ShopsObsolete} {
+		for _, event := range eventList {
+			if !event.IsSeparator() {
+				entries = append(entries, event)
+			}
+		}
+	}
+
+	// detect collisions
+	bySlug := make(map[string]*Event)
+	collisions := make([]string, 0)
+	for _, event := range entries {
+		slug := event.Slug()
+		if other, found := bySlug[slug]; found {
+			collisions = append(collisions, fmt.Sprintf("'%s' is used by %s and %s", slug, other.describe(), event.describe()))
+			continue
+		}
+		bySlug[slug] = event
+	}
+	if len(collisions) > 0 {
+		return fmt.Errorf("slug collisions (set the SLUG column of one of the entries): %s", strings.Join(collisions, "; "))
+	}
+
+	keys := r.assignKeys(entries)
+
+	redirects := make([]utils.Redirect, 0)
+	for _, event := range entries {
+		key := keys[event]
+		slug := event.Slug()
+		slugs := make([]string, 0, len(r.Entries[key])+1)
+		for _, old := range r.Entries[key] {
+			if old == slug {
+				continue
+			}
+			slugs = append(slugs, old)
+			// never shadow a page of this run
+			if _, current := bySlug[old]; !current {
+				redirects = append(redirects, utils.Redirect{From: "/" + old, To: "/" + slug})
+			}
+		}
+		r.Entries[key] = append(slugs, slug)
+	}
+
+	sort.Slice(redirects, func(i, j int) bool { return redirects[i].From < redirects[j].From })
+	data.SlugRedirects = redirects
+	return nil
+}
+
+// assignKeys returns the registry record of each entry and removes the records no entry claims (their entries are
+// gone). An entry claims the record ending with its slug; a renamed entry claims the only unclaimed record of its
+// identity, if it is the only entry of that identity without a record. All other entries get a new record, so they
+// never inherit the slugs of another entry.
+func (r *SlugRegistry) assignKeys(entries []*Event) map[*Event]string {
+	keys := make(map[*Event]string)
+	claimed := make(map[string]bool)
+
+	bySlug := make(map[string]string)
+	for key, slugs := range r.Entries {
+		if len(slugs) > 0 {
+			bySlug[slugs[len(slugs)-1]] = key
+		}
+	}
+	unmatched := make(map[string][]*Event)
+	for _, event := range entries {
+		if key, found := bySlug[event.Slug()]; found {
+			keys[event] = key
+			claimed[key] = true
+		} else {
+			unmatched[event.Identity()] = append(unmatched[event.Identity()], event)
+		}
+	}
+
+	unclaimed := make([]string, 0)
+	for key := range r.Entries {
+		if !claimed[key] {
+			unclaimed = append(unclaimed, key)
+		}
+	}
+	sort.Strings(unclaimed)
+	for _, event := range entries {
+		identity := event.Identity()
+		if _, found := keys[event]; found || len(unmatched[identity]) != 1 {
+			continue
+		}
+		candidates := make([]string, 0)
+		for _, key := range unclaimed {
+			if !claimed[key] && (key == identity || strings.HasPrefix(key, identity+"|")) {
+				candidates = append(candidates, key)
+			}
+		}
+		if len(candidates) == 1 {
+			keys[event] = candidates[0]
+			claimed[candidates[0]] = true
+		}
+	}
+
+	for key := range r.Entries {
+		if !claimed[key] {
+			delete(r.Entries, key)
+		}
+	}
+	for _, event := range entries {
+		if _, found := keys[event]; found {
+			continue
+		}
+		identity := event.Identity()
+		key := identity
+		if claimed[key] {
+			key = fmt.Sprintf("%s|%s", identity, event.Slug())
+		}
+		for i := 2; claimed[key]; i++ {
+			key = fmt.Sprintf("%s|%s|%d", identity, event.Slug(), i)
+		}
+		keys[event] = key
+		claimed[key] = true
+	}
+	return keys
+}
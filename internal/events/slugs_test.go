@@ -0,0 +1,118 @@
+package events
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func testEntry(t *testing.T, name string, date string, url string) *Event {
+	timeRange, err := utils.CreateTimeRange(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Event{Type: "event", Name: utils.NewName(name), Time: timeRange, MainLink: utils.CreateUnnamedLink(url)}
+}
+
+func TestSlugRegistry(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "slugs.json")
+	registry, err := LoadSlugRegistry(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{Events: []*Event{testEntry(t, "Trail Lauf", "17.05.2026", "https://www.example.com/trail/")}}
+	if err := registry.Update(&data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.SlugRedirects) != 0 {
+		t.Errorf("expected no redirects, got %v", data.SlugRedirects)
+	}
+	if err := registry.Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	// rename: the old slug redirects to the new one
+	registry, err = LoadSlugRegistry(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = Data{Events: []*Event{testEntry(t, "Odenwald Trail", "24.05.2026", "http://example.com/trail")}}
+	if err := registry.Update(&data); err != nil {
+		t.Fatal(err)
+	}
+	expected := utils.Redirect{From: "/event/2026-trail-lauf.html", To: "/event/2026-odenwald-trail.html"}
+	if len(data.SlugRedirects) != 1 || data.SlugRedirects[0] != expected {
+		t.Errorf("expected redirect %v, got %v", expected, data.SlugRedirects)
+	}
+	if slugs := registry.Entries["event|2026|example.com/trail"]; len(slugs) != 2 || slugs[1] != "event/2026-odenwald-trail.html" {
+		t.Errorf("unexpected registry entry %v", slugs)
+	}
+
+	// a new entry taking over the old slug wins over the redirect
+	data = Data{Events: []*Event{
+		testEntry(t, "Odenwald Trail", "24.05.2026", "https://example.com/trail"),
+		testEntry(t, "Trail Lauf", "01.09.2026", "https://example.org/"),
+	}}
+	if err := registry.Update(&data); err != nil {
+		t.Fatal(err)
+	}
+	if len(data.SlugRedirects) != 0 {
+		t.Errorf("expected no redirects, got %v", data.SlugRedirects)
+	}
+
+	// collisions fail, unless a SLUG override separates the entries
+	collision := testEntry(t, "Odenwald-Trail", "01.10.2026", "https://example.net/")
+	data = Data{Events: []*Event{testEntry(t, "Odenwald Trail", "24.05.2026", "https://example.com/trail"), collision}}
+	if err := registry.Update(&data); err == nil || !strings.Contains(err.Error(), "event/2026-odenwald-trail.html") {
+		t.Errorf("expected collision error, got %v", err)
+	}
+	collision.Meta.Slug = "odenwald-trail-herbst"
+	if err := registry.Update(&data); err != nil {
+		t.Errorf("unexpected error with SLUG override: %v", err)
+	}
+}
+
+func TestSlugRegistrySharedIdentity(t *testing.T) {
+	registry := &SlugRegistry{make(map[string][]string)}
+	update := func(events ...*Event) []utils.Redirect {
+		data := Data{Events: events}
+		if err := registry.Update(&data); err != nil {
+			t.Fatal(err)
+		}
+		return data.SlugRedirects
+	}
+	stadtlauf := func() *Event { return testEntry(t, "Stadtlauf", "14.06.2026", "https://example.com/") }
+	nachtlauf := func(name string) *Event { return testEntry(t, name, "12.12.2026", "https://example.com/") }
+
+	update(stadtlauf())
+	// a second entry with the same URL and year gets its own record
+	if redirects := update(stadtlauf(), nachtlauf("Nachtlauf")); len(redirects) != 0 {
+		t.Errorf("expected no redirects, got %v", redirects)
+	}
+	// renames of entries sharing the URL are tracked
+	expected := utils.Redirect{From: "/event/2026-nachtlauf.html", To: "/event/2026-mondscheinlauf.html"}
+	if redirects := update(stadtlauf(), nachtlauf("Mondscheinlauf")); len(redirects) != 1 || redirects[0] != expected {
+		t.Errorf("expected redirect %v, got %v", expected, redirects)
+	}
+	// removing the other entry neither redirects its slug nor leaves its record behind
+	if redirects := update(nachtlauf("Mondscheinlauf")); len(redirects) != 1 || redirects[0] != expected {
+		t.Errorf("expected redirect %v, got %v", expected, redirects)
+	}
+	for key, slugs := range registry.Entries {
+		for _, slug := range slugs {
+			if slug == "event/2026-stadtlauf.html" {
+				t.Errorf("stale record %s: %v", key, slugs)
+			}
+		}
+	}
+	if len(registry.Entries) != 1 {
+		t.Errorf("expected 1 record, got %v", registry.Entries)
+	}
+	// a new entry with the URL of the removed one does not inherit its slugs
+	if redirects := update(nachtlauf("Mondscheinlauf"), stadtlauf()); len(redirects) != 1 || redirects[0] != expected {
+		t.Errorf("expected redirect %v, got %v", expected, redirects)
+	}
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// Snapshot is a copy of the raw spreadsheet tables; it allows to generate the site without access to Google Sheets.
+type Snapshot struct {
+	Sheets []string              `json:"sheets"`
+	Tables map[string][][]string `json:"tables"`
+}
+
+// TakeSnapshot copies all tables of src (except the ignored ones).
+func TakeSnapshot(src TableSource) (*Snapshot, error) {
+	names, err := src.SheetNames()
+	if err != nil {
+		return nil, fmt.Errorf("fetching all sheets: %w", err)
+	}
+
+	snapshot := &Snapshot{make([]string, 0, len(names)), make(map[string][][]string)}
+	for _, name := range names {
+		if strings.Contains(name, "ignore") {
+			continue
+		}
+		values, err := src.Table(name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot fetch table '%s': %w", name, err)
+		}
+		rows := make([][]string, 0, len(values))
+		for _, row := range values {
+			cells := make([]string, 0, len(row))
+			for _, value := range row {
+				cells = append(cells, fmt.Sprintf("%v", value))
+			}
+			rows = append(rows, cells)
+		}
+		snapshot.Sheets = append(snapshot.Sheets, name)
+		snapshot.Tables[name] = rows
+	}
+	return snapshot, nil
+}
+
+func LoadSnapshot(path string) (*Snapshot, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load snapshot file '%s': %w", path, err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(buf, &snapshot); err != nil {
+		return nil, fmt.Errorf("unmarshall snapshot data: %w", err)
+	}
+	return &snapshot, nil
+}
+
+func (s *Snapshot) Save(path string) error {
+	return utils.WriteJSON(path, s)
+}
+
+func (s *Snapshot) SheetNames() ([]string, error) {
+	return s.Sheets, nil
+}
+
+func (s *Snapshot) Table(name string) ([][]interface{}, error) {
+	rows, ok := s.Tables[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown table '%s'", name)
+	}
+	values := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		cells := make([]interface{}, 0, len(row))
+		for _, cell := range row {
+			cells = append(cells, cell)
+		}
+		values = append(values, cells)
+	}
+	return values, nil
+}
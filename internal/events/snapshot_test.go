@@ -0,0 +1,73 @@
+package events
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testSnapshot() *Snapshot {
+	eventColumns := []string{"DATE", "NAME", "NAME2", "SEO", "STATUS", "URL", "DESCRIPTION", "LOCATION", "COORDINATES", "REGISTRATION", "TAGS", "LINK1"}
+	return &Snapshot{
+		[]string{"Events2025", "Events2026", "Groups", "Shops", "Parkrun", "Tags", "Series", "Notes (ignore)"},
+		map[string][][]string{
+			"Events2025": {
+				eventColumns,
+				{"10.05.2025", "Maienlauf", "", "", "", "https://example.com/mai", "Ein Lauf im Mai", "Heidelberg", "49.41,8.69", "", "Volkslauf", "Ergebnisse|https://example.com/res"},
+			},
+			"Events2026": {
+				eventColumns,
+				{"17.05.2026", "Odenwald Trail", "", "", "", "https://example.com/trail", "Trail im Odenwald", "Wald-Michelbach", "49.57,8.83", "", "Traillauf", ""},
+				{"07.06.2026", "Brückenlauf", "", "", "abgesagt", "https://example.com/bruecke", "", "Mannheim", "49.49,8.47", "", "", ""},
+			},
+			"Groups": {
+				eventColumns,
+				{"", "Lauftreff Altstadt", "", "", "", "https://example.com/lt", "Jeden Dienstag", "Heidelberg", "49.41,8.71", "", "", ""},
+			},
+			"Shops":   {eventColumns},
+			"Parkrun": {{"DATE", "INDEX", "RUNNERS", "TEMP", "SPECIAL", "CAFE", "RESULTS", "REPORT", "AUTHOR", "PHOTOS"}},
+			"Tags": {
+				{"TAG", "NAME", "DESCRIPTION"},
+				{"traillauf", "Traillauf", "Läufe abseits befestigter Wege"},
+			},
+			"Series": {{"NAME", "DESCRIPTION", "LINK1"}},
+		},
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := testSnapshot().Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := LoadSnapshot(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a snapshot of a snapshot drops ignored sheets only
+	copied, err := TakeSnapshot(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copied.Sheets) != 7 {
+		t.Errorf("expected 7 sheets, got %v", copied.Sheets)
+	}
+
+	data, err := FetchDataFrom(copied, time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := NonSeparators(data.Events); n != 2 {
+		t.Errorf("expected 2 upcoming events, got %d", n)
+	}
+	if n := NonSeparators(data.EventsOld); n != 1 {
+		t.Errorf("expected 1 old event, got %d", n)
+	}
+	if len(data.Groups) != 1 || data.Groups[0].Name.Orig != "Lauftreff Altstadt" {
+		t.Errorf("unexpected groups %v", data.Groups)
+	}
+	if _, err := snapshot.Table("Unknown"); err == nil {
+		t.Errorf("expected error for unknown table")
+	}
+}
@@ -13,10 +13,11 @@ type Tag struct {
 	EventsOld   []*Event
 	Groups      []*Event
 	Shops       []*Event
+	Region      bool // entries are assigned automatically by their coordinates (see LoadRegions)
 }
 
 func CreateTag(name string) *Tag {
-	return &Tag{utils.NewName(name), "", make([]*Event, 0), make([]*Event, 0), make([]*Event, 0), make([]*Event, 0)}
+	return &Tag{utils.NewName(name), "", make([]*Event, 0), make([]*Event, 0), make([]*Event, 0), make([]*Event, 0), false}
 }
 
 func (tag *Tag) Slug() string {
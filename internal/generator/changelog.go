@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// ChangelogItem is a change as shown on the changelog page of a locale; Link is empty if the entry is gone.
+type ChangelogItem struct {
+	events.ChangeRecord
+	Title string
+	Link  string
+}
+
+// ChangelogWeek groups the changes of a calendar week.
+type ChangelogWeek struct {
+	Label string
+	Items []ChangelogItem
+}
+
+type ChangelogTemplateData struct {
+	TemplateData
+	Weeks []ChangelogWeek
+}
+
+// changelogFeedLength is the maximum number of entries of the changelog feed.
+const changelogFeedLength = 50
+
+func changeTitle(record events.ChangeRecord, locale i18n.Locale) string {
+	switch record.Kind {
+	case events.ChangeNew:
+		switch record.Type {
+		case "group":
+			return locale.T("changelog.new-group", record.Name)
+		case "shop":
+			return locale.T("changelog.new-shop", record.Name)
+		}
+		if record.New != "" {
+			return locale.T("changelog.new-date", record.Name, record.New)
+		}
+		return locale.T("changelog.new", record.Name)
+	case events.ChangeCancelled:
+		return locale.T("changelog.cancelled", record.Name)
+	case events.ChangeDate:
+		return locale.T("changelog.date", record.Name, record.New, record.Old)
+	}
+	return record.Name
+}
+
+// changelogItems returns the changes of data, newest first, linked to the entries' pages of the locale.
+func changelogItems(data events.Data, locale i18n.Locale) []ChangelogItem {
+	current := make(map[string]bool)
+	for _, eventList := range [][]*events.Event{data.Events, data.EventsOld, data.Groups, data.Shops} {
+		for _, event := range eventList {
+			if !event.IsSeparator() {
+				current[event.Slug()] = true
+			}
+		}
+	}
+	items := make([]ChangelogItem, 0, len(data.Changes))
+	for i := len(data.Changes) - 1; i >= 0; i-- {
+		record := data.Changes[i]
+		link := ""
+		if current[record.Slug] {
+			link = "/" + locale.Path(record.Slug)
+		}
+		items = append(items, ChangelogItem{record, changeTitle(record, locale), link})
+	}
+	return items
+}
+
+func changelogWeeks(items []ChangelogItem, locale i18n.Locale) []ChangelogWeek {
+	weeks := make([]ChangelogWeek, 0)
+	lastKey := ""
+	for _, item := range items {
+		date, err := time.Parse("2006-01-02", item.Date)
+		if err != nil {
+			continue
+		}
+		year, week := date.ISOWeek()
+		key := fmt.Sprintf("%d-%02d", year, week)
+		if key != lastKey {
+			monday := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+			sunday := monday.AddDate(0, 0, 6)
+			label := locale.T("changelog.week", week, locale.FormatDay(monday, false), locale.FormatDay(sunday, true))
+			weeks = append(weeks, ChangelogWeek{label, make([]ChangelogItem, 0)})
+			lastKey = key
+		}
+		weeks[len(weeks)-1].Items = append(weeks[len(weeks)-1].Items, item)
+	}
+	return weeks
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string    `xml:"title"`
+	Id      string    `xml:"id"`
+	Link    *atomLink `xml:"link,omitempty"`
+	Updated string    `xml:"updated"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Id      string      `xml:"id"`
+	Links   []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  string      `xml:"author>name"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+func atomDate(date string) string {
+	return date + "T00:00:00Z"
+}
+
+// createChangelogFeed writes the newest changes as Atom feed of the locale; its timestamp is the date of the newest
+// change (so the file only changes with the changes).
+func createChangelogFeed(items []ChangelogItem, baseUrl utils.Url, now time.Time, locale i18n.Locale, fileName string) error {
+	page := baseUrl.Join(locale.Path("changelog.html"))
+	feed := atomFeed{
+		Title: locale.T("changelog.feed.title"),
+		Id:    page,
+		Links: []atomLink{
+			{baseUrl.Join(locale.Path("changelog.xml")), "self", "application/atom+xml"},
+			{page, "alternate", "text/html"},
+		},
+		Updated: atomDate(now.Format("2006-01-02")),
+		Author:  "heidelberg.run",
+		Entries: make([]atomEntry, 0),
+	}
+	if len(items) > 0 {
+		feed.Updated = atomDate(items[0].Date)
+	}
+	for i, item := range items {
+		if i >= changelogFeedLength {
+			break
+		}
+		entry := atomEntry{
+			Title:   item.Title,
+			Id:      fmt.Sprintf("%s#%s-%s-%s", page, item.Date, item.Kind, item.Slug),
+			Updated: atomDate(item.Date),
+		}
+		if item.Link != "" {
+			entry.Link = &atomLink{Href: baseUrl.Join(strings.TrimPrefix(item.Link, "/"))}
+		} else {
+			entry.Link = &atomLink{Href: page}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	buf, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal changelog feed: %w", err)
+	}
+	if _, err := utils.WriteFileIfChanged(fileName, append([]byte(xml.Header), buf...)); err != nil {
+		return fmt.Errorf("write changelog feed: %w", err)
+	}
+	return nil
+}
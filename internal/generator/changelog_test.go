@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestChangelog(t *testing.T) {
+	timeRange, err := utils.CreateTimeRange("10.05.2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := events.Data{
+		Events: []*events.Event{{Type: "event", Name: utils.NewName("Maienlauf"), Time: timeRange}},
+		Changes: []events.ChangeRecord{
+			{Date: "2026-03-01", Kind: events.ChangeNew, Type: "group", Name: "Lauftreff", Slug: "group/lauftreff.html"},
+			{Date: "2026-03-03", Kind: events.ChangeNew, Type: "event", Name: "Maienlauf", Slug: "event/2026-maienlauf.html", New: "10.05.2026"},
+			{Date: "2026-03-04", Kind: events.ChangeDate, Type: "event", Name: "Stadtlauf", Slug: "event/2026-stadtlauf.html", Old: "14.06.2026", New: "21.06.2026"},
+		},
+	}
+
+	items := changelogItems(data, i18n.DE)
+	weeks := changelogWeeks(items, i18n.DE)
+	if len(weeks) != 2 || weeks[0].Label != "KW 10: 02.03. – 08.03.2026" || len(weeks[0].Items) != 2 || weeks[1].Label != "KW 9: 23.02. – 01.03.2026" {
+		t.Fatalf("unexpected weeks %+v", weeks)
+	}
+	if item := weeks[0].Items[0]; item.Title != "Neuer Termin: Stadtlauf (21.06.2026 statt 14.06.2026)" || item.Link != "" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if item := weeks[0].Items[1]; item.Title != "Neu: Maienlauf (10.05.2026)" || item.Link != "/event/2026-maienlauf.html" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	if item := weeks[1].Items[0]; item.Title != "Neuer Lauftreff: Lauftreff" {
+		t.Errorf("unexpected item %+v", item)
+	}
+
+	items = changelogItems(data, i18n.EN)
+	weeks = changelogWeeks(items, i18n.EN)
+	if len(weeks) != 2 || weeks[0].Label != "Week 10: 2 March – 8 March 2026" {
+		t.Fatalf("unexpected weeks %+v", weeks)
+	}
+	if item := weeks[0].Items[1]; item.Title != "New: Maienlauf (10.05.2026)" || item.Link != "/en/event/2026-maienlauf.html" {
+		t.Errorf("unexpected item %+v", item)
+	}
+	items = changelogItems(data, i18n.DE)
+
+	fileName := filepath.Join(t.TempDir(), "changelog.xml")
+	if err := createChangelogFeed(items, "https://example.com", time.Now(), i18n.DE, fileName); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := string(buf)
+	for _, expected := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<updated>2026-03-04T00:00:00Z</updated>`,
+		`<link href="https://example.com/event/2026-maienlauf.html"></link>`,
+		`<id>https://example.com/changelog.html#2026-03-01-new-group/lauftreff.html</id>`,
+	} {
+		if !strings.Contains(feed, expected) {
+			t.Errorf("feed does not contain %s:\n%s", expected, feed)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/api"
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+type EmbedNear struct {
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	RadiusKM float64 `json:"radius_km"`
+}
+
+// EmbedConfig describes a single embeddable event list.
+type EmbedConfig struct {
+	Slug    string     `json:"slug"`
+	Title   string     `json:"title"`
+	Tag     string     `json:"tag,omitempty"`
+	Serie   string     `json:"serie,omitempty"`
+	Country *string    `json:"country,omitempty"` // "" selects Germany
+	Near    *EmbedNear `json:"near,omitempty"`
+	Columns []string   `json:"columns,omitempty"`
+	Limit   int        `json:"limit,omitempty"`
+	Width   int        `json:"width,omitempty"`
+	Height  int        `json:"height,omitempty"`
+}
+
+var embedColumns = map[string]struct{}{
+	"name":     {},
+	"date":     {},
+	"location": {},
+	"distance": {},
+	"status":   {},
+	"tags":     {},
+}
+
+var defaultEmbedColumns = []string{"name", "status", "date", "location"}
+
+func LoadEmbedConfig(path string) ([]EmbedConfig, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load embed config file '%s': %w", path, err)
+	}
+	var config struct {
+		Embeds []EmbedConfig `json:"embeds"`
+	}
+	if err := json.Unmarshal(buf, &config); err != nil {
+		return nil, fmt.Errorf("unmarshall embed config data: %w", err)
+	}
+
+	for i := range config.Embeds {
+		embed := &config.Embeds[i]
+		if utils.SanitizeName(embed.Slug) != embed.Slug || embed.Slug == "" {
+			return nil, fmt.Errorf("embed config: bad slug '%s'", embed.Slug)
+		}
+		if embed.Tag != "" && embed.Serie != "" {
+			return nil, fmt.Errorf("embed config '%s': only one of 'tag' and 'serie' may be set", embed.Slug)
+		}
+		if embed.Near != nil && embed.Near.RadiusKM <= 0 {
+			return nil, fmt.Errorf("embed config '%s': 'near' needs a positive 'radius_km'", embed.Slug)
+		}
+		if len(embed.Columns) == 0 {
+			embed.Columns = defaultEmbedColumns
+		}
+		for _, column := range embed.Columns {
+			if _, ok := embedColumns[column]; !ok {
+				return nil, fmt.Errorf("embed config '%s': unknown column '%s'", embed.Slug, column)
+			}
+		}
+		if embed.Width == 0 {
+			embed.Width = 400
+		}
+		if embed.Height == 0 {
+			embed.Height = 600
+		}
+	}
+	return config.Embeds, nil
+}
+
+func (e EmbedConfig) HtmlSlug() string {
+	return fmt.Sprintf("embed/%s.html", e.Slug)
+}
+
+func (e EmbedConfig) JsonSlug() string {
+	return fmt.Sprintf("embed/%s.json", e.Slug)
+}
+
+func (e EmbedConfig) OEmbedSlug() string {
+	return fmt.Sprintf("embed/%s.oembed.json", e.Slug)
+}
+
+func (e EmbedConfig) HasColumn(name string) bool {
+	for _, column := range e.Columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e EmbedConfig) sourceEvents(data *events.Data) ([]*events.Event, error) {
+	if e.Tag != "" {
+		sanitized := utils.SanitizeName(e.Tag)
+		for _, tag := range data.Tags {
+			if tag.Name.Sanitized == sanitized {
+				return tag.Events, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown tag '%s'", e.Tag)
+	}
+	if e.Serie != "" {
+		sanitized := utils.SanitizeName(e.Serie)
+		for _, serie := range data.Series {
+			if serie.Name.Sanitized == sanitized {
+				return serie.Events, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown series '%s'", e.Serie)
+	}
+	return data.Events, nil
+}
+
+// EmbedEvent is an event together with its distance to the embed's center point (if any).
+type EmbedEvent struct {
+	*events.Event
+	DistanceKM float64
+}
+
+func (e EmbedEvent) Distance() string {
+	if e.DistanceKM >= 0 {
+		return fmt.Sprintf("%.1fkm", e.DistanceKM)
+	}
+	return e.Location.Dir()
+}
+
+// Select returns the upcoming events matching the embed's filters.
+func (e EmbedConfig) Select(data *events.Data) ([]EmbedEvent, error) {
+	source, err := e.sourceEvents(data)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := make([]EmbedEvent, 0)
+	for _, event := range source {
+		if event.IsSeparator() {
+			continue
+		}
+		if e.Country != nil && event.Location.Country != *e.Country {
+			continue
+		}
+		distance := -1.0
+		if e.Near != nil {
+			if !event.Location.HasGeo() {
+				continue
+			}
+			distance, _ = utils.DistanceBearing(e.Near.Lat, e.Near.Lon, event.Location.Lat, event.Location.Lon)
+			if distance > e.Near.RadiusKM {
+				continue
+			}
+		}
+		selected = append(selected, EmbedEvent{event, distance})
+		if e.Limit > 0 && len(selected) >= e.Limit {
+			break
+		}
+	}
+	return selected, nil
+}
+
+type EmbedListTemplateData struct {
+	TemplateData
+	Embed     EmbedConfig
+	Events    []EmbedEvent
+	OEmbedUrl string
+}
+
+type embedPayload struct {
+	api.Envelope
+	Title  string      `json:"title"`
+	Url    string      `json:"url"`
+	Events []api.Event `json:"events"`
+}
+
+type oEmbed struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Title        string `json:"title"`
+	ProviderName string `json:"provider_name"`
+	ProviderUrl  string `json:"provider_url"`
+	CacheAge     int    `json:"cache_age"`
+	Html         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+func renderEmbed(baseUrl utils.Url, out utils.Path, now time.Time, data TemplateData, embed EmbedConfig) error {
+	selected, err := embed.Select(data.Data)
+	if err != nil {
+		return fmt.Errorf("select events for embed '%s': %w", embed.Slug, err)
+	}
+
+	t := EmbedListTemplateData{
+		TemplateData: data,
+		Embed:        embed,
+		Events:       selected,
+		OEmbedUrl:    baseUrl.Join(embed.OEmbedSlug()),
+	}
+	t.Title = embed.Title
+	t.Canonical = baseUrl.Join(embed.HtmlSlug())
+	if err := utils.ExecuteTemplate("embed-list", out.Join(embed.HtmlSlug()), t); err != nil {
+		return fmt.Errorf("render embed list for %q: %w", embed.HtmlSlug(), err)
+	}
+
+	eventList := make([]*events.Event, 0, len(selected))
+	for _, e := range selected {
+		eventList = append(eventList, e.Event)
+	}
+	// the payload is not one of the files described by api/v1/schema.json
+	envelope := api.NewWriter(out, baseUrl, now).Envelope()
+	envelope.Schema = ""
+	payload := embedPayload{
+		envelope,
+		embed.Title,
+		t.Canonical,
+		api.ConvertEvents(eventList, baseUrl),
+	}
+	if err := utils.WriteJSON(out.Join(embed.JsonSlug()), payload); err != nil {
+		return err
+	}
+
+	descriptor := oEmbed{
+		Version:      "1.0",
+		Type:         "rich",
+		Title:        embed.Title,
+		ProviderName: "heidelberg.run",
+		ProviderUrl:  string(baseUrl),
+		CacheAge:     86400,
+		Html: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="%s" style="border:0" loading="lazy"></iframe>`,
+			html.EscapeString(t.Canonical), embed.Width, embed.Height, html.EscapeString(embed.Title)),
+		Width:  embed.Width,
+		Height: embed.Height,
+	}
+	return utils.WriteJSON(out.Join(embed.OEmbedSlug()), descriptor)
+}
@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestLoadEmbedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "embeds.json")
+	write := func(s string) {
+		if err := os.WriteFile(path, []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"embeds": [{"slug": "trailrun-fr", "title": "Trail", "tag": "traillauf", "country": "Frankreich"}]}`)
+	embeds, err := LoadEmbedConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(embeds) != 1 || embeds[0].Country == nil || *embeds[0].Country != "Frankreich" {
+		t.Fatalf("unexpected config %+v", embeds)
+	}
+	if !embeds[0].HasColumn("date") || embeds[0].HasColumn("distance") {
+		t.Errorf("unexpected default columns %v", embeds[0].Columns)
+	}
+
+	for _, bad := range []string{
+		`{"embeds": [{"slug": "Bad Slug"}]}`,
+		`{"embeds": [{"slug": "x", "tag": "a", "serie": "b"}]}`,
+		`{"embeds": [{"slug": "x", "near": {"lat": 49.4, "lon": 8.7}}]}`,
+		`{"embeds": [{"slug": "x", "columns": ["foo"]}]}`,
+	} {
+		write(bad)
+		if _, err := LoadEmbedConfig(path); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestEmbedSelect(t *testing.T) {
+	newEvent := func(name, location, coordinates string) *events.Event {
+		return &events.Event{Type: "event", Name: utils.NewName(name), Location: events.CreateLocation(location, coordinates)}
+	}
+	heidelberg := newEvent("Heidelberg", "Heidelberg", "49.4094,8.6942")
+	mannheim := newEvent("Mannheim", "Mannheim", "49.4875,8.4660")
+	wissembourg := newEvent("Wissembourg", "Wissembourg, FR", "49.03,7.94")
+	nogeo := newEvent("Irgendwo", "Irgendwo", "")
+	tag := &events.Tag{Name: utils.NewName("Traillauf"), Events: []*events.Event{heidelberg, mannheim, wissembourg, nogeo}}
+	data := &events.Data{Events: tag.Events, Tags: []*events.Tag{tag}}
+
+	germany := ""
+	tests := []struct {
+		embed    EmbedConfig
+		expected []*events.Event
+	}{
+		{EmbedConfig{Tag: "traillauf", Country: &germany}, []*events.Event{heidelberg, mannheim, nogeo}},
+		{EmbedConfig{Near: &EmbedNear{49.41, 8.69, 10}}, []*events.Event{heidelberg}},
+		{EmbedConfig{Near: &EmbedNear{49.41, 8.69, 100}, Limit: 2}, []*events.Event{heidelberg, mannheim}},
+		{EmbedConfig{Tag: "traillauf", Limit: 1}, []*events.Event{heidelberg}},
+	}
+	for i, test := range tests {
+		selected, err := test.embed.Select(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(selected) != len(test.expected) {
+			t.Errorf("%d: got %d events; want %d", i, len(selected), len(test.expected))
+			continue
+		}
+		for j, e := range selected {
+			if e.Event != test.expected[j] {
+				t.Errorf("%d: event %d = %s; want %s", i, j, e.Name.Orig, test.expected[j].Name.Orig)
+			}
+		}
+	}
+
+	if _, err := (EmbedConfig{Serie: "unknown"}).Select(data); err == nil {
+		t.Errorf("expected error for unknown series")
+	}
+}
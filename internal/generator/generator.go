@@ -2,31 +2,37 @@ package generator
 
 import (
 	"fmt"
-	"os"
+	"html/template"
 	"strings"
 	"time"
 
+	"github.com/svengiegerich/heidelberg-run/internal/api"
 	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
+	"github.com/svengiegerich/heidelberg-run/internal/ogimage"
 	"github.com/svengiegerich/heidelberg-run/internal/resources"
+	"github.com/svengiegerich/heidelberg-run/internal/search"
+	"github.com/svengiegerich/heidelberg-run/internal/stats"
 	"github.com/svengiegerich/heidelberg-run/internal/utils"
 )
 
 type UmamiData struct {
-	Url string
-	Id  string
+	Id string
 }
 
 type CommonData struct {
 	Timestamp       string
 	TimestampFull   string
 	BaseUrl         string
-	BasePath        string
 	FeedbackFormUrl string // URL for feedback form
 	SheetUrl        string
 	Data            *events.Data
-	JsFiles         []string
-	CssFiles        []string
+	JsFiles         []utils.Asset
+	CssFiles        []utils.Asset
 	Umami           UmamiData
+	Locale          i18n.Locale
+	Locales         []i18n.Locale // locales the page is available in (nil: only the default locale)
+	SearchIndex     string        // site-relative path of the hashed search index
 }
 
 type TemplateData struct {
@@ -37,6 +43,7 @@ type TemplateData struct {
 	Canonical   string
 	Breadcrumbs utils.Breadcrumbs
 	Main        string
+	ShareImage  string // path of the generated share image (relative to BaseUrl)
 }
 
 func (t *TemplateData) SetNameLink(name, link string, baseBreakcrumbs utils.Breadcrumbs, baseUrl utils.Url) {
@@ -46,13 +53,45 @@ func (t *TemplateData) SetNameLink(name, link string, baseBreakcrumbs utils.Brea
 }
 
 func (t TemplateData) Image() string {
+	if t.ShareImage != "" {
+		return utils.Url(t.BaseUrl).Join(t.ShareImage)
+	}
 	return "https://heidelberg.run/images/512.png"
 }
 
+type Alternate struct {
+	Locale i18n.Locale
+	Name   string
+	Url    string
+}
+
+func (a Alternate) Hreflang() string {
+	return string(a.Locale)
+}
+
+// Alternates returns the URLs of the page in all locales it is available in (default locale first).
+func (t TemplateData) Alternates() []Alternate {
+	if len(t.Locales) == 0 {
+		return nil
+	}
+	baseUrl := utils.Url(t.BaseUrl)
+	slug := strings.TrimPrefix(strings.TrimPrefix(t.Canonical, t.BaseUrl), "/")
+	slug = t.Locale.StripPath(slug)
+	alternates := make([]Alternate, 0, len(t.Locales))
+	for _, locale := range t.Locales {
+		alternates = append(alternates, Alternate{locale, locale.T("language"), baseUrl.Join(locale.Path(slug))})
+	}
+	return alternates
+}
+
 func (t TemplateData) NiceTitle() string {
 	return t.Title
 }
 
+func (t TemplateData) StructuredData() template.JS {
+	return marshalJsonLD(createBreadcrumbList(t.Breadcrumbs, utils.Url(t.BaseUrl)))
+}
+
 func (t TemplateData) CountEvents() int {
 	count := 0
 	for _, event := range t.Data.Events {
@@ -92,6 +131,13 @@ func (d EventTemplateData) NiceTitle() string {
 	return fmt.Sprintf("%s %s", d.Title, yearS)
 }
 
+func (d EventTemplateData) StructuredData() template.JS {
+	return marshalJsonLD(
+		createEventJsonLD(d.Event, d.Canonical, d.Description, d.Image()),
+		createBreadcrumbList(d.Breadcrumbs, utils.Url(d.BaseUrl)),
+	)
+}
+
 type TagTemplateData struct {
 	TemplateData
 	Tag *events.Tag
@@ -101,18 +147,22 @@ func (d TagTemplateData) NiceTitle() string {
 	return d.Title
 }
 
-type SerieTemplateData struct {
+type CityTemplateData struct {
 	TemplateData
-	Serie *events.Serie
+	City *events.City
 }
 
-func (d SerieTemplateData) NiceTitle() string {
+func (d CityTemplateData) NiceTitle() string {
 	return d.Title
 }
 
-type EmbedListTemplateData struct {
+type SerieTemplateData struct {
 	TemplateData
-	Events []*events.Event
+	Serie *events.Serie
+}
+
+func (d SerieTemplateData) NiceTitle() string {
+	return d.Title
 }
 
 type SitemapTemplateData struct {
@@ -124,154 +174,71 @@ func (d SitemapTemplateData) NiceTitle() string {
 	return d.Title
 }
 
-func createHtaccess(data events.Data, outDir utils.Path) error {
-	if err := utils.MakeDir(outDir.String()); err != nil {
-		return err
-	}
-
-	fileName := outDir.Join(".htaccess")
-
-	destination, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
-	defer destination.Close()
-
-	destination.WriteString("ErrorDocument 404 /404.html\n")
-	destination.WriteString("Redirect /parkrun /bahnstadtpromenade-parkrun.html\n")
-	destination.WriteString("Redirect /groups.html /lauftreffs.html\n")
-	destination.WriteString("Redirect /event/bahnstadtpromenade-parkrun.html /group/bahnstadtpromenade-parkrun.html\n")
-	destination.WriteString("Redirect /tag/2025.html /events-old.html\n")
-	destination.WriteString("Redirect /tag/2026.html /\n")
-
-	for _, e := range data.Events {
-		slug := e.Slug()
-		if old := e.SlugOld(); old != "" {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", old, slug))
-		}
-		if slugNoBase := e.SlugNoBase(); slugNoBase != slug {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", slugNoBase, slug))
-		}
-	}
-	for _, e := range data.EventsOld {
-		slug := e.Slug()
-		if old := e.SlugOld(); old != "" {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", old, slug))
-		}
-		if slugNoBase := e.SlugNoBase(); slugNoBase != slug {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", slugNoBase, slug))
-		}
-	}
-	for _, e := range data.Groups {
-		if old := e.SlugOld(); old != "" {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", old, e.Slug()))
-		}
-	}
-	for _, e := range data.Shops {
-		if old := e.SlugOld(); old != "" {
-			destination.WriteString(fmt.Sprintf("Redirect /%s /%s\n", old, e.Slug()))
-		}
-	}
-
-	for _, e := range data.EventsObsolete {
-		destination.WriteString(fmt.Sprintf("Redirect /%s /\n", e.Slug()))
-	}
-	for _, e := range data.GroupsObsolete {
-		destination.WriteString(fmt.Sprintf("Redirect /%s /lauftreffs.html\n", e.Slug()))
-	}
-	for _, e := range data.ShopsObsolete {
-		destination.WriteString(fmt.Sprintf("Redirect /%s /shops.html\n", e.Slug()))
-	}
-
-	return nil
-}
-
-type CountryData struct {
-	slug   string
-	events []*events.Event
-}
-
-func renderEmbedList(baseUrl utils.Url, out utils.Path, data TemplateData, tag *events.Tag) error {
-	countryData := map[string]*CountryData{
-		"":           {"embed/trailrun-de.html", make([]*events.Event, 0)}, // Default (Germany)
-		"Frankreich": {"embed/trailrun-fr.html", make([]*events.Event, 0)},
-		"Schweiz":    {"embed/trailrun-ch.html", make([]*events.Event, 0)},
-	}
-
-	// Distribute events into the appropriate country-specific data
-	for _, event := range tag.Events {
-		if event.IsSeparator() {
-			continue
-		}
-		if d, ok := countryData[event.Location.Country]; ok {
-			d.events = append(d.events, event)
-		} else {
-			return fmt.Errorf("Country '%s' not found in countrySlugs", event.Location.Country)
-		}
-	}
-
-	// Render templates for each country
-	for _, d := range countryData {
-		t := EmbedListTemplateData{
-			TemplateData: data,
-			Events:       d.events,
-		}
-		t.Canonical = baseUrl.Join(d.slug)
-		if err := utils.ExecuteTemplate("embed-list", out.Join(d.slug), t.BasePath, t); err != nil {
-			return fmt.Errorf("render embed list for %q: %w", d.slug, err)
-		}
-	}
-
-	return nil
-}
-
 type Generator struct {
 	out             utils.Path
 	baseUrl         utils.Url
-	basePath        string
 	now             time.Time
 	timestamp       string
 	timestampFull   string
-	jsFiles         []string
-	cssFiles        []string
-	umamiScript     string
+	assetManifest   string
 	umamiId         string
 	feedbackFormUrl string
 	sheetUrl        string
 	hashFile        string
+	imageCacheDir   string
+	embeds          []EmbedConfig
+	redirects       []RedirectBackend
+	workers         int
 }
 
 func NewGenerator(
 	out utils.Path,
-	baseUrl utils.Url, basePath string,
+	baseUrl utils.Url,
 	now time.Time,
-	jsFiles []string, cssFiles []string,
-	umamiScript string, umamiId string,
+	assetManifest string,
+	umamiId string,
 	feedbackFormUrl string, sheetUrl string,
 	hashFile string,
+	imageCacheDir string,
+	embeds []EmbedConfig,
+	redirectBackends []RedirectBackend,
+	workers int,
 ) Generator {
 	return Generator{
 		out:             out,
 		baseUrl:         baseUrl,
-		basePath:        basePath,
 		now:             now,
 		timestamp:       now.Format("2006-01-02"),
 		timestampFull:   now.Format("2006-01-02 15:04:05"),
-		jsFiles:         jsFiles,
-		cssFiles:        cssFiles,
-		umamiScript:     umamiScript,
+		assetManifest:   assetManifest,
 		umamiId:         umamiId,
 		feedbackFormUrl: feedbackFormUrl,
 		sheetUrl:        sheetUrl,
 		hashFile:        hashFile,
+		imageCacheDir:   imageCacheDir,
+		embeds:          embeds,
+		redirects:       redirectBackends,
+		workers:         workers,
 	}
 }
 
 func (g Generator) Generate(eventsData events.Data) error {
 	// Prepare assets
+	manifest, err := resources.LoadManifest(g.assetManifest)
+	if err != nil {
+		return err
+	}
 	resourceManager := resources.NewResourceManager(".", string(g.out))
-	resourceManager.CopyExternalAssets()
-	resourceManager.CopyStaticAssets()
+	resourceManager.CopyAssets(manifest)
+	if resourceManager.Error != nil {
+		return fmt.Errorf("copy assets: %w", resourceManager.Error)
+	}
+
+	// Create search index
+	searchIndex, err := search.Build(eventsData).Write(g.out)
+	if err != nil {
+		return fmt.Errorf("create search index: %w", err)
+	}
 
 	// create ics files for events
 	createCalendarsForEvents := func(eventList []*events.Event) error {
@@ -301,307 +268,508 @@ func (g Generator) Generate(eventsData events.Data) error {
 		return fmt.Errorf("create events.ics: %v", err)
 	}
 
+	// Create static JSON API
+	if err := api.NewWriter(g.out, g.baseUrl, g.now).Write(eventsData); err != nil {
+		return fmt.Errorf("create json api: %w", err)
+	}
+	if err := api.WriteGeoJSON(g.out, eventsData); err != nil {
+		return fmt.Errorf("create geojson: %w", err)
+	}
+
+	shareImages, err := ogimage.NewRenderer("static/512.png", g.imageCacheDir)
+	if err != nil {
+		return fmt.Errorf("create share image renderer: %w", err)
+	}
+	renderShareImage := func(card ogimage.Card) (string, error) {
+		return shareImages.Render(card, g.out)
+	}
+
 	sitemap := utils.CreateSitemap(g.baseUrl)
 	sitemap.AddCategory("Allgemein")
 	sitemap.AddCategory("Laufveranstaltungen")
 	sitemap.AddCategory("Vergangene Laufveranstaltungen")
 	sitemap.AddCategory("Kategorien")
 	sitemap.AddCategory("Serien")
+	sitemap.AddCategory("Orte")
 	sitemap.AddCategory("Lauftreffs")
 	sitemap.AddCategory("Lauf-Shops")
-
-	breadcrumbsBase := utils.InitBreadcrumbs(utils.CreateLink("heidelberg.run", "/"))
-	breadcrumbsEvents := breadcrumbsBase.Push(utils.CreateLink("Laufveranstaltungen", "/"))
-	breadcrumbsTags := breadcrumbsEvents.Push(utils.CreateLink("Kategorien", "/tags.html"))
-	breadcrumbsSeries := breadcrumbsEvents.Push(utils.CreateLink("Serien", "/series.html"))
-	breadcrumbsGroups := breadcrumbsBase.Push(utils.CreateLink("Lauftreffs", "/lauftreffs.html"))
-	breadcrumbsShops := breadcrumbsBase.Push(utils.CreateLink("Lauf-Shops", "/shops.html"))
-	breadcrumbsInfo := breadcrumbsBase.Push(utils.CreateLink("Info", "/info.html"))
+	sitemap.AddCategory("English")
 
 	commondata := CommonData{
 		g.timestamp,
 		g.timestampFull,
 		string(g.baseUrl),
-		g.basePath,
 		g.feedbackFormUrl,
 		g.sheetUrl,
 		&eventsData,
 		resourceManager.JsFiles,
 		resourceManager.CssFiles,
 		UmamiData{
-			resourceManager.UmamiScript,
 			g.umamiId,
 		},
+		i18n.Default,
+		nil,
+		searchIndex,
 	}
 
-	// Render general pages
-	renderPage := func(slug, slugFile, template, nav, sitemapCategory, title, description string, breadcrumbs utils.Breadcrumbs) error {
-		data := TemplateData{
-			commondata,
-			title,
-			description,
-			nav,
-			g.baseUrl.Join(slug),
-			breadcrumbs,
-			"/",
+	// Pages are rendered in parallel; the sitemap entries are added in order by the dispatching goroutine.
+	workers := newPool(g.workers)
+
+	// Render the pages that exist in every locale (default locale at the root, others below /<locale>/)
+	statistics := stats.Compute(eventsData, g.now)
+	renderLocale := func(locale i18n.Locale) error {
+		commondata := commondata
+		commondata.Locale = locale
+		commondata.Locales = i18n.Locales
+
+		sitemapCategory := func(category string) string {
+			if locale.IsDefault() {
+				return category
+			}
+			return "English"
 		}
-		if err := utils.ExecuteTemplate(template, g.out.Join(slugFile), data.BasePath, data); err != nil {
-			return fmt.Errorf("render template %q to %q: %w", template, g.out.Join(slugFile), err)
+		localLink := func(name, url string) *utils.Link {
+			return utils.CreateLink(name, "/"+locale.Path(strings.TrimPrefix(url, "/")))
 		}
-		if template != "404" {
-			sitemap.Add(slug, slugFile, title, sitemapCategory)
+
+		breadcrumbsBase := utils.InitBreadcrumbs(localLink("heidelberg.run", "/"))
+		breadcrumbsEvents := breadcrumbsBase.Push(localLink(locale.T("breadcrumbs.events"), "/"))
+		breadcrumbsTags := breadcrumbsEvents.Push(localLink(locale.T("breadcrumbs.tags"), "/tags.html"))
+		breadcrumbsSeries := breadcrumbsEvents.Push(localLink(locale.T("breadcrumbs.series"), "/series.html"))
+		breadcrumbsGroups := breadcrumbsBase.Push(localLink(locale.T("breadcrumbs.groups"), "/lauftreffs.html"))
+		breadcrumbsShops := breadcrumbsBase.Push(localLink(locale.T("breadcrumbs.shops"), "/shops.html"))
+		breadcrumbsCities := breadcrumbsBase.Push(localLink(locale.T("breadcrumbs.cities"), "/orte.html"))
+
+		// Render general pages
+		renderPage := func(slug, slugFile, template, nav, sitemapCat, title, description string, breadcrumbs utils.Breadcrumbs) error {
+			data := TemplateData{
+				commondata,
+				title,
+				description,
+				nav,
+				g.baseUrl.Join(locale.Path(slug)),
+				breadcrumbs,
+				"/" + locale.Path(""),
+				"",
+			}
+			fileName := g.out.Join(locale.Path(slugFile))
+			if err := utils.ExecuteTemplateLocale(template, fileName, locale, data); err != nil {
+				return fmt.Errorf("render template %q to %q: %w", template, fileName, err)
+			}
+			if template != "404" {
+				sitemap.Add(locale.Path(slug), locale.Path(slugFile), title, sitemapCategory(sitemapCat))
+			}
+			return nil
+		}
+		renderSubPage := func(slug, slugFile, template, nav, sitemapCat, title, description string, breadcrumbsParent utils.Breadcrumbs) error {
+			breadcrumbs := breadcrumbsParent.Push(localLink(title, "/"+slug))
+			return renderPage(slug, slugFile, template, nav, sitemapCat, title, description, breadcrumbs)
 		}
-		return nil
-	}
-	renderSubPage := func(slug, slugFile, template, nav, sitemapCategory, title, description string, breadcrumbsParent utils.Breadcrumbs) error {
-		breadcrumbs := breadcrumbsParent.Push(utils.CreateLink(title, "/"+slug))
-		return renderPage(slug, slugFile, template, nav, sitemapCategory, title, description, breadcrumbs)
-	}
 
-	if err := renderPage("", "index.html", "events", "events", "Laufveranstaltungen",
-		"Laufveranstaltungen im Raum Heidelberg",
-		"Liste von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum Heidelberg",
-		breadcrumbsEvents); err != nil {
-		return fmt.Errorf("render index page: %w", err)
-	}
+		if err := renderPage("", "index.html", "events", "events", "Laufveranstaltungen",
+			locale.T("page.events.title"),
+			locale.T("page.events.description"),
+			breadcrumbsEvents); err != nil {
+			return fmt.Errorf("render index page: %w", err)
+		}
 
-	if err := renderPage("tags.html", "tags.html", "tags", "tags", "Kategorien",
-		"Kategorien",
-		"Liste aller Kategorien von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum Heidelberg",
-		breadcrumbsTags); err != nil {
-		return fmt.Errorf("render tags page: %w", err)
-	}
+		if err := renderPage("tags.html", "tags.html", "tags", "tags", "Kategorien",
+			locale.T("page.tags.title"),
+			locale.T("page.tags.description"),
+			breadcrumbsTags); err != nil {
+			return fmt.Errorf("render tags page: %w", err)
+		}
 
-	if err := renderPage("lauftreffs.html", "lauftreffs.html", "groups", "groups", "Lauftreffs",
-		"Lauftreffs im Raum Heidelberg",
-		"Liste von Lauftreffs, Laufgruppen, Lauf-Trainingsgruppen im Raum Heidelberg",
-		breadcrumbsGroups); err != nil {
-		return fmt.Errorf("render groups page: %w", err)
-	}
+		if err := renderPage("lauftreffs.html", "lauftreffs.html", "groups", "groups", "Lauftreffs",
+			locale.T("page.groups.title"),
+			locale.T("page.groups.description"),
+			breadcrumbsGroups); err != nil {
+			return fmt.Errorf("render groups page: %w", err)
+		}
 
-	if err := renderPage("shops.html", "shops.html", "shops", "shops", "Lauf-Shops",
-		"Lauf-Shops im Raum Heidelberg",
-		"Liste von Lauf-Shops und Einzelhandelsgeschäften mit Laufschuh-Auswahl im Raum Heidelberg",
-		breadcrumbsShops); err != nil {
-		return fmt.Errorf("render shops page: %w", err)
-	}
-	
-	if err := renderPage("series.html", "series.html", "series", "series", "Serien",
-		"Lauf-Serien",
-		"Liste aller Serien von Laufveranstaltungen, Lauf-Wettkämpfen, Volksläufen im Raum Heidelberg",
-		breadcrumbsSeries); err != nil {
-		return fmt.Errorf("render series page: %w", err)
-	}
+		if err := renderPage("shops.html", "shops.html", "shops", "shops", "Lauf-Shops",
+			locale.T("page.shops.title"),
+			locale.T("page.shops.description"),
+			breadcrumbsShops); err != nil {
+			return fmt.Errorf("render shops page: %w", err)
+		}
 
-	if err := renderSubPage("map.html", "map.html", "map", "map", "Allgemein",
-		"Karte aller Laufveranstaltungen",
-		"Karte",
-		breadcrumbsBase); err != nil {
-		return fmt.Errorf("render subpage %q: %w", "map.html", err)
-	}
+		if err := renderPage("series.html", "series.html", "series", "series", "Serien",
+			locale.T("page.series.title"),
+			locale.T("page.series.description"),
+			breadcrumbsSeries); err != nil {
+			return fmt.Errorf("render series page: %w", err)
+		}
 
-	if err := renderPage("info.html", "info.html", "info", "info", "Allgemein",
-		"Info",
-		"Kontaktmöglichkeiten, allgemeine & technische Informationen über heidelberg.run",
-		breadcrumbsInfo); err != nil {
-		return fmt.Errorf("render info page: %w", err)
-	}
+		if err := renderPage("orte.html", "orte.html", "cities", "cities", "Orte",
+			locale.T("page.cities.title"),
+			locale.T("page.cities.description"),
+			breadcrumbsCities); err != nil {
+			return fmt.Errorf("render cities page: %w", err)
+		}
 
-	if err := renderSubPage("datenschutz.html", "datenschutz.html", "datenschutz", "datenschutz", "Allgemein",
-		"Datenschutz",
-		"Datenschutzerklärung von heidelberg.run",
-		breadcrumbsInfo); err != nil {
-		return fmt.Errorf("render subpage %q: %w", "datenschutz.html", err)
-	}
+		if err := renderSubPage("map.html", "map.html", "map", "map", "Allgemein",
+			locale.T("page.map.title"),
+			locale.T("page.map.description"),
+			breadcrumbsBase); err != nil {
+			return fmt.Errorf("render subpage %q: %w", "map.html", err)
+		}
 
-	if err := renderSubPage("impressum.html", "impressum.html", "impressum", "impressum", "Allgemein",
-		"Impressum",
-		"Impressum von heidelberg.run",
-		breadcrumbsInfo); err != nil {
-		return fmt.Errorf("render subpage %q: %w", "impressum.html", err)
-	}
+		if err := renderSubPage("404.html", "404.html", "404", "404", "",
+			locale.T("page.404.title"),
+			locale.T("page.404.description"),
+			breadcrumbsBase); err != nil {
+			return fmt.Errorf("render subpage %q: %w", "404.html", err)
+		}
 
-	if err := renderSubPage("404.html", "404.html", "404", "404", "",
-		"404 - Seite nicht gefunden :(",
-		"Fehlerseite von heidelberg.run",
-		breadcrumbsBase); err != nil {
-		return fmt.Errorf("render subpage %q: %w", "404.html", err)
-	}
+		// Render changelog page and feed
+		changelogItems := changelogItems(eventsData, locale)
+		changelogData := ChangelogTemplateData{
+			TemplateData{
+				commondata,
+				locale.T("page.changelog.title"),
+				locale.T("page.changelog.description"),
+				"changelog",
+				g.baseUrl.Join(locale.Path("changelog.html")),
+				breadcrumbsBase.Push(localLink(locale.T("page.changelog.title"), "/changelog.html")),
+				"/" + locale.Path(""),
+				"",
+			},
+			changelogWeeks(changelogItems, locale),
+		}
+		changelogFile := g.out.Join(locale.Path("changelog.html"))
+		if err := utils.ExecuteTemplateLocale("changelog", changelogFile, locale, changelogData); err != nil {
+			return fmt.Errorf("render changelog template to %q: %w", changelogFile, err)
+		}
+		sitemap.Add(locale.Path("changelog.html"), locale.Path("changelog.html"), changelogData.Title, sitemapCategory("Allgemein"))
+		if err := createChangelogFeed(changelogItems, g.baseUrl, g.now, locale, g.out.Join(locale.Path("changelog.xml"))); err != nil {
+			return err
+		}
 
-	data := TemplateData{commondata, "", "", "", "", breadcrumbsBase, "/"}
-
-	// Render old events lists
-	oldYearsLinks := make(map[string]*utils.Link)
-	oldYears := make([]*utils.Link, 0, len(eventsData.OldEvents))
-	for index, oldEvents := range eventsData.OldEvents {
-		url := "/events-old.html"
-		if index != 0 {
-			url = fmt.Sprintf("/events-old-%s.html", oldEvents.Year)
-		}
-		oldYearsLinks[oldEvents.Year] = utils.CreateLink(
-			fmt.Sprintf("Vergangene Laufveranstaltungen (%s)", oldEvents.Year),
-			url,
-		)
-		oldYears = append(oldYears, utils.CreateLink(
-			oldEvents.Year,
-			url,
-		))
-	}
-	for index, oldEvents := range eventsData.OldEvents {
-		name := fmt.Sprintf("Vergangene Laufveranstaltungen (%s)", oldEvents.Year)
-		fname := "events-old.html"
-		if index != 0 {
-			fname = fmt.Sprintf("events-old-%s.html", oldEvents.Year)
-		}
-		data := OldEventsTemplateData{
-			TemplateData: TemplateData{
+		// Render statistics page and charts
+		statsData := StatsTemplateData{
+			TemplateData{
 				commondata,
-				name,
-				"BLUBB",
-				"events",
+				locale.T("page.stats.title"),
+				locale.T("page.stats.description"),
+				"stats",
+				g.baseUrl.Join(locale.Path("statistik.html")),
+				breadcrumbsBase.Push(localLink(locale.T("page.stats.title"), "/statistik.html")),
+				"/" + locale.Path(""),
 				"",
-				breadcrumbsEvents,
-				"/",
 			},
-			Year:   oldEvents.Year,
-			Years:  oldYears,
-			Events: oldEvents.Events,
+			statistics,
+			statsLabels(locale),
+		}
+		statsFile := g.out.Join(locale.Path("statistik.html"))
+		if err := utils.ExecuteTemplateLocale("statistik", statsFile, locale, statsData); err != nil {
+			return fmt.Errorf("render statistics template to %q: %w", statsFile, err)
+		}
+		sitemap.Add(locale.Path("statistik.html"), locale.Path("statistik.html"), statsData.Title, sitemapCategory("Allgemein"))
+		if err := writeStatsCharts(statsData.Stats, statsData.Labels, g.out, locale); err != nil {
+			return err
 		}
-		data.SetNameLink(name, fname, breadcrumbsEvents, g.baseUrl)
 
-		if err := utils.ExecuteTemplate("events-old", g.out.Join(fname), data.BasePath, data); err != nil {
-			return fmt.Errorf("render old events template for %q: %w", oldEvents.Year, err)
+		// Render old events lists
+		oldYearsLinks := make(map[string]*utils.Link)
+		oldYears := make([]*utils.Link, 0, len(eventsData.OldEvents))
+		for index, oldEvents := range eventsData.OldEvents {
+			url := "/events-old.html"
+			if index != 0 {
+				url = fmt.Sprintf("/events-old-%s.html", oldEvents.Year)
+			}
+			oldYearsLinks[oldEvents.Year] = localLink(
+				locale.T("page.events-old.title", oldEvents.Year),
+				url,
+			)
+			oldYears = append(oldYears, localLink(
+				oldEvents.Year,
+				url,
+			))
 		}
-		sitemap.Add(fname, fname, name, "Vergangene Laufveranstaltungen")
-	}
+		for index, oldEvents := range eventsData.OldEvents {
+			name := locale.T("page.events-old.title", oldEvents.Year)
+			fname := "events-old.html"
+			if index != 0 {
+				fname = fmt.Sprintf("events-old-%s.html", oldEvents.Year)
+			}
+			data := OldEventsTemplateData{
+				TemplateData: TemplateData{
+					commondata,
+					name,
+					"BLUBB",
+					"events",
+					"",
+					breadcrumbsEvents,
+					"/" + locale.Path(""),
+					"",
+				},
+				Year:   oldEvents.Year,
+				Years:  oldYears,
+				Events: oldEvents.Events,
+			}
+			data.SetNameLink(name, locale.Path(fname), breadcrumbsEvents, g.baseUrl)
 
-	// Render events, groups, shops lists
-	renderEventList := func(eventList []*events.Event, nav, main, sitemapCategory string, breadcrumbs utils.Breadcrumbs) error {
-		eventdata := EventTemplateData{
+			year := oldEvents.Year
+			workers.Go(func() error {
+				if err := utils.ExecuteTemplateLocale("events-old", g.out.Join(locale.Path(fname)), locale, data); err != nil {
+					return fmt.Errorf("render old events template for %q: %w", year, err)
+				}
+				return nil
+			})
+			sitemap.Add(locale.Path(fname), locale.Path(fname), name, sitemapCategory("Vergangene Laufveranstaltungen"))
+		}
+
+		// Render events, groups, shops lists
+		renderEventList := func(eventList []*events.Event, nav, main, sitemapCat string, breadcrumbs utils.Breadcrumbs) error {
+			main = "/" + locale.Path(strings.TrimPrefix(main, "/"))
+			base := EventTemplateData{
+				TemplateData{
+					commondata,
+					"",
+					"",
+					nav,
+					"",
+					breadcrumbs,
+					main,
+					"",
+				},
+				nil,
+			}
+			for _, event := range eventList {
+				if event.IsSeparator() {
+					continue
+				}
+
+				eventdata := base
+				eventdata.Main = main
+				parentBreadcrumbs := breadcrumbs
+				if event.Old {
+					if link, ok := oldYearsLinks[fmt.Sprintf("%d", event.Time.Year())]; ok {
+						eventdata.Main = link.Url
+						parentBreadcrumbs = parentBreadcrumbs.Push(link)
+					}
+				}
+
+				eventdata.Event = event
+				eventdata.Description = event.GenerateDescriptionIn(locale)
+				slug := locale.Path(event.Slug())
+				fileSlug := locale.Path(event.SlugFile())
+				name := event.Name.Orig
+				if event.Meta.SeoTitle != "" {
+					name = event.Meta.SeoTitle
+				}
+				eventdata.SetNameLink(name, slug, parentBreadcrumbs, g.baseUrl)
+				card := ogimage.Card{Title: event.Name.Orig, Date: event.Time.Localized(locale), Place: event.Location.NameNoFlag(), Badge: event.NiceTypeIn(locale)}
+				if len(event.Tags) > 0 {
+					card.Badge = event.Tags[0].Name.Orig
+				}
+				workers.Go(func() error {
+					var err error
+					if eventdata.ShareImage, err = renderShareImage(card); err != nil {
+						return err
+					}
+					if err := utils.ExecuteTemplateLocale("event", g.out.Join(fileSlug), locale, eventdata); err != nil {
+						return fmt.Errorf("render event template to %q: %w", g.out.Join(fileSlug), err)
+					}
+					return nil
+				})
+				sitemap.Add(slug, fileSlug, event.Name.Orig, sitemapCategory(sitemapCat))
+			}
+			return nil
+		}
+		if err := renderEventList(eventsData.Events, "events", "/", "Laufveranstaltungen", breadcrumbsEvents); err != nil {
+			return fmt.Errorf("render event list: %w", err)
+		}
+		if err := renderEventList(eventsData.EventsOld, "events", "/events-old.html", "Vergangene Laufveranstaltungen", breadcrumbsEvents); err != nil {
+			return fmt.Errorf("render old event list: %w", err)
+		}
+		if err := renderEventList(eventsData.Groups, "groups", "/lauftreffs.html", "Lauftreffs", breadcrumbsGroups); err != nil {
+			return fmt.Errorf("render group event list: %w", err)
+		}
+		if err := renderEventList(eventsData.Shops, "shops", "/shops.html", "Lauf-Shops", breadcrumbsShops); err != nil {
+			return fmt.Errorf("render shop event list: %w", err)
+		}
+
+		// Render tags
+		tagbase := TagTemplateData{
 			TemplateData{
 				commondata,
 				"",
 				"",
-				nav,
+				"tags",
+				"",
+				breadcrumbsTags,
+				"/" + locale.Path("tags.html"),
 				"",
-				breadcrumbs,
-				main,
 			},
 			nil,
 		}
-		for _, event := range eventList {
-			if event.IsSeparator() {
-				continue
-			}
+		for _, tag := range eventsData.Tags {
+			tagdata := tagbase
+			tagdata.Tag = tag
+			tagdata.Description = locale.T("page.tag.description", tag.Name.Orig)
+			slug := locale.Path(tag.Slug())
+			tagdata.SetNameLink(tag.Name.Orig, slug, breadcrumbsTags, g.baseUrl)
+			tagdata.Title = locale.T("page.tag.title", tag.Name.Orig)
+			card := ogimage.Card{Title: tagdata.Title, Date: locale.T("card.events", tag.NumEvents()), Badge: tag.Name.Orig}
+			workers.Go(func() error {
+				var err error
+				if tagdata.ShareImage, err = renderShareImage(card); err != nil {
+					return err
+				}
+				if err := utils.ExecuteTemplateLocale("tag", g.out.Join(slug), locale, tagdata); err != nil {
+					return fmt.Errorf("render tag template to %q: %w", g.out.Join(slug), err)
+				}
+				return nil
+			})
+			sitemap.Add(slug, slug, tag.Name.Orig, sitemapCategory("Kategorien"))
+		}
 
-			eventdata.Main = main
-			parentBreadcrumbs := breadcrumbs
-			if event.Old {
-				if link, ok := oldYearsLinks[fmt.Sprintf("%d", event.Time.Year())]; ok {
-					eventdata.Main = link.Url
-					parentBreadcrumbs = parentBreadcrumbs.Push(link)
+		// Render cities
+		citybase := CityTemplateData{
+			TemplateData{
+				commondata,
+				"",
+				"",
+				"cities",
+				"",
+				breadcrumbsCities,
+				"/" + locale.Path("orte.html"),
+				"",
+			},
+			nil,
+		}
+		for _, city := range eventsData.Cities {
+			citydata := citybase
+			citydata.City = city
+			citydata.Description = locale.T("page.city.description", city.Name.Orig)
+			slug := locale.Path(city.Slug())
+			citydata.SetNameLink(city.Name.Orig, slug, breadcrumbsCities, g.baseUrl)
+			citydata.Title = locale.T("page.city.title", city.Name.Orig)
+			card := ogimage.Card{Title: citydata.Title, Date: locale.T("card.events", city.NumEvents()), Badge: city.Name.Orig}
+			workers.Go(func() error {
+				var err error
+				if citydata.ShareImage, err = renderShareImage(card); err != nil {
+					return err
 				}
-			}
+				if err := utils.ExecuteTemplateLocale("city", g.out.Join(slug), locale, citydata); err != nil {
+					return fmt.Errorf("render city template to %q: %w", g.out.Join(slug), err)
+				}
+				return nil
+			})
+			sitemap.Add(slug, slug, city.Name.Orig, sitemapCategory("Orte"))
+		}
 
-			eventdata.Event = event
-			eventdata.Description = event.GenerateDescription()
-			slug := event.Slug()
-			fileSlug := event.SlugFile()
-			name := event.Name.Orig
-			if event.Meta.SeoTitle != "" {
-				name = event.Meta.SeoTitle
+		// Render series
+		renderSeries := func(series []*events.Serie) error {
+			base := SerieTemplateData{
+				TemplateData{
+					commondata,
+					"",
+					"",
+					"series",
+					"",
+					breadcrumbsSeries,
+					"/" + locale.Path("series.html"),
+					"",
+				},
+				nil,
 			}
-			eventdata.SetNameLink(name, slug, parentBreadcrumbs, g.baseUrl)
-			if err := utils.ExecuteTemplate("event", g.out.Join(fileSlug), eventdata.BasePath, eventdata); err != nil {
-				return fmt.Errorf("render event template to %q: %w", g.out.Join(fileSlug), err)
+			for _, s := range series {
+				seriedata := base
+				seriedata.Serie = s
+				seriedata.Description = locale.T("page.serie.description", s.Name.Orig)
+				slug := locale.Path(s.Slug())
+				seriedata.SetNameLink(s.Name.Orig, slug, breadcrumbsSeries, g.baseUrl)
+				card := ogimage.Card{Title: s.Name.Orig, Date: locale.T("card.events", events.NonSeparators(s.Events)), Badge: locale.T("serie.series")}
+				workers.Go(func() error {
+					var err error
+					if seriedata.ShareImage, err = renderShareImage(card); err != nil {
+						return err
+					}
+					if err := utils.ExecuteTemplateLocale("serie", g.out.Join(slug), locale, seriedata); err != nil {
+						return fmt.Errorf("render serie template to %q: %w", g.out.Join(slug), err)
+					}
+					return nil
+				})
+				sitemap.Add(slug, slug, s.Name.Orig, sitemapCategory("Serien"))
 			}
-			sitemap.Add(slug, fileSlug, event.Name.Orig, sitemapCategory)
+			return nil
 		}
+		if err := renderSeries(eventsData.Series); err != nil {
+			return fmt.Errorf("render series: %w", err)
+		}
+		if err := renderSeries(eventsData.SeriesOld); err != nil {
+			return fmt.Errorf("render old series: %w", err)
+		}
+
 		return nil
 	}
-	if err := renderEventList(eventsData.Events, "events", "/", "Laufveranstaltungen", breadcrumbsEvents); err != nil {
-		return fmt.Errorf("render event list: %w", err)
-	}
-	if err := renderEventList(eventsData.EventsOld, "events", "/events-old.html", "Vergangene Laufveranstaltungen", breadcrumbsEvents); err != nil {
-		return fmt.Errorf("render old event list: %w", err)
-	}
-	if err := renderEventList(eventsData.Groups, "groups", "/lauftreffs.html", "Lauftreffs", breadcrumbsGroups); err != nil {
-		return fmt.Errorf("render group event list: %w", err)
+	for _, locale := range i18n.Locales {
+		if err := renderLocale(locale); err != nil {
+			workers.Wait()
+			return fmt.Errorf("render locale '%s': %w", locale, err)
+		}
 	}
-	if err := renderEventList(eventsData.Shops, "shops", "/shops.html", "Lauf-Shops", breadcrumbsShops); err != nil {
-		return fmt.Errorf("render shop event list: %w", err)
+	if err := workers.Wait(); err != nil {
+		return err
 	}
 
-	// Render tags
-	tagdata := TagTemplateData{
-		TemplateData{
+	// Render pages that only exist in the default locale
+	breadcrumbsBase := utils.InitBreadcrumbs(utils.CreateLink("heidelberg.run", "/"))
+	breadcrumbsInfo := breadcrumbsBase.Push(utils.CreateLink("Info", "/info.html"))
+	renderPage := func(slug, template, nav, title, description string, breadcrumbs utils.Breadcrumbs) error {
+		data := TemplateData{
 			commondata,
+			title,
+			description,
+			nav,
+			g.baseUrl.Join(slug),
+			breadcrumbs,
+			"/",
 			"",
-			"",
-			"tags",
-			"",
-			breadcrumbsTags,
-			"/tags.html",
-		},
-		nil,
-	}
-	for _, tag := range eventsData.Tags {
-		tagdata.Tag = tag
-		tagdata.Description = fmt.Sprintf("Laufveranstaltungen der Kategorie '%s' im Raum Heidelberg; Vollständige Übersicht mit Terminen, Details und Anmeldelinks für alle Events dieser Kategorie.", tag.Name.Orig)
-		slug := tag.Slug()
-		tagdata.SetNameLink(tag.Name.Orig, slug, breadcrumbsTags, g.baseUrl)
-		tagdata.Title = fmt.Sprintf("Laufveranstaltungen der Kategorie '%s'", tag.Name.Orig)
-		if err := utils.ExecuteTemplate("tag", g.out.Join(slug), tagdata.BasePath, tagdata); err != nil {
-			return fmt.Errorf("render tag template to %q: %w", g.out.Join(slug), err)
-		}
-		sitemap.Add(slug, slug, tag.Name.Orig, "Kategorien")
+		}
+		if err := utils.ExecuteTemplate(template, g.out.Join(slug), data); err != nil {
+			return fmt.Errorf("render template %q to %q: %w", template, g.out.Join(slug), err)
+		}
+		sitemap.Add(slug, slug, title, "Allgemein")
+		return nil
 	}
 
-	// Special rendering of the "traillauf" tag
-	for _, tag := range eventsData.Tags {
-		if tag.Name.Sanitized == "traillauf" {
-			if err := renderEmbedList(g.baseUrl, g.out, data, tag); err != nil {
-				return fmt.Errorf("create embed lists: %v", err)
-			}
-			break
-		}
+	if err := renderPage("info.html", "info", "info",
+		"Info",
+		"Kontaktmöglichkeiten, allgemeine & technische Informationen über heidelberg.run",
+		breadcrumbsInfo); err != nil {
+		return fmt.Errorf("render info page: %w", err)
 	}
 
-	// Render series
-	renderSeries := func(series []*events.Serie) error {
-		seriedata := SerieTemplateData{
-			TemplateData{
-				commondata,
-				"",
-				"",
-				"series",
-				"",
-				breadcrumbsSeries,
-				"/series.html",
-			},
-			nil,
-		}
-		for _, s := range series {
-			seriedata.Serie = s
-			seriedata.Description = fmt.Sprintf("Lauf-Serie '%s'", s.Name)
-			slug := s.Slug()
-			seriedata.SetNameLink(s.Name.Orig, slug, breadcrumbsSeries, g.baseUrl)
-			if err := utils.ExecuteTemplate("serie", g.out.Join(slug), seriedata.BasePath, seriedata); err != nil {
-				return fmt.Errorf("render serie template to %q: %w", g.out.Join(slug), err)
-			}
-			sitemap.Add(slug, slug, s.Name.Orig, "Serien")
-		}
-		return nil
+	if err := renderPage("datenschutz.html", "datenschutz", "datenschutz",
+		"Datenschutz",
+		"Datenschutzerklärung von heidelberg.run",
+		breadcrumbsInfo.Push(utils.CreateLink("Datenschutz", "/datenschutz.html"))); err != nil {
+		return fmt.Errorf("render subpage %q: %w", "datenschutz.html", err)
 	}
-	if err := renderSeries(eventsData.Series); err != nil {
-		return fmt.Errorf("render series: %w", err)
+
+	if err := renderPage("impressum.html", "impressum", "impressum",
+		"Impressum",
+		"Impressum von heidelberg.run",
+		breadcrumbsInfo.Push(utils.CreateLink("Impressum", "/impressum.html"))); err != nil {
+		return fmt.Errorf("render subpage %q: %w", "impressum.html", err)
 	}
-	if err := renderSeries(eventsData.SeriesOld); err != nil {
-		return fmt.Errorf("render old series: %w", err)
+
+	// Render embeddable event lists
+	data := TemplateData{commondata, "", "", "", "", breadcrumbsBase, "/", ""}
+	for _, embed := range g.embeds {
+		if err := renderEmbed(g.baseUrl, g.out, g.now, data, embed); err != nil {
+			return fmt.Errorf("create embed lists: %v", err)
+		}
 	}
 
 	// Render sitemap
-	sitemap.Gen(g.out.Join("sitemap.xml"), g.hashFile, g.out)
+	if err := sitemap.Gen(g.out.Join("sitemap.xml"), g.hashFile, g.out, g.now); err != nil {
+		return fmt.Errorf("create sitemap.xml: %w", err)
+	}
 	sitemapTemplate := SitemapTemplateData{
 		TemplateData{
 			commondata,
@@ -611,16 +779,17 @@ func (g Generator) Generate(eventsData events.Data) error {
 			fmt.Sprintf("%s/sitemap.html", g.baseUrl),
 			breadcrumbsBase.Push(utils.CreateLink("Sitemap", "/sitemap.html")),
 			"/",
+			"",
 		},
 		sitemap.GenHTML(),
 	}
-	if err := utils.ExecuteTemplate("sitemap", g.out.Join("sitemap.html"), sitemapTemplate.BasePath, sitemapTemplate); err != nil {
+	if err := utils.ExecuteTemplate("sitemap", g.out.Join("sitemap.html"), sitemapTemplate); err != nil {
 		return fmt.Errorf("render sitemap template to %q: %w", g.out.Join("sitemap.html"), err)
 	}
 
-	// Render .htaccess
-	if err := createHtaccess(eventsData, g.out); err != nil {
-		return fmt.Errorf("create .htaccess: %v", err)
+	// Render redirects
+	if err := g.createRedirects(eventsData); err != nil {
+		return fmt.Errorf("create redirects: %v", err)
 	}
 
 	return nil
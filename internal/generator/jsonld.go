@@ -0,0 +1,226 @@
+package generator
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+const schemaOrg = "https://schema.org"
+
+type ldThing struct {
+	Context string `json:"@context,omitempty"`
+	Type    string `json:"@type"`
+}
+
+type ldListItem struct {
+	ldThing
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	Item     string `json:"item"`
+}
+
+type ldBreadcrumbList struct {
+	ldThing
+	ItemListElement []ldListItem `json:"itemListElement"`
+}
+
+type ldPostalAddress struct {
+	ldThing
+	AddressLocality string `json:"addressLocality"`
+	AddressCountry  string `json:"addressCountry"`
+}
+
+type ldGeoCoordinates struct {
+	ldThing
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type ldPlace struct {
+	ldThing
+	Name    string            `json:"name"`
+	Address ldPostalAddress   `json:"address"`
+	Geo     *ldGeoCoordinates `json:"geo,omitempty"`
+}
+
+type ldOrganization struct {
+	ldThing
+	Name string `json:"name"`
+	Url  string `json:"url"`
+}
+
+type ldOffer struct {
+	ldThing
+	Url string `json:"url"`
+}
+
+type ldSportsEvent struct {
+	ldThing
+	Name                string          `json:"name"`
+	Description         string          `json:"description,omitempty"`
+	Url                 string          `json:"url"`
+	Image               string          `json:"image,omitempty"`
+	StartDate           string          `json:"startDate"`
+	EndDate             string          `json:"endDate"`
+	EventStatus         string          `json:"eventStatus"`
+	EventAttendanceMode string          `json:"eventAttendanceMode"`
+	Location            ldPlace         `json:"location"`
+	Offers              *ldOffer        `json:"offers,omitempty"`
+	Organizer           *ldOrganization `json:"organizer,omitempty"`
+}
+
+type ldLocalBusiness struct {
+	ldThing
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Url         string            `json:"url"`
+	SameAs      string            `json:"sameAs,omitempty"`
+	Image       string            `json:"image,omitempty"`
+	Address     ldPostalAddress   `json:"address"`
+	Geo         *ldGeoCoordinates `json:"geo,omitempty"`
+}
+
+func countryCode(country string) string {
+	switch country {
+	case "Frankreich":
+		return "FR"
+	case "Schweiz":
+		return "CH"
+	default:
+		return "DE"
+	}
+}
+
+func createPostalAddress(location events.Location) ldPostalAddress {
+	return ldPostalAddress{ldThing{Type: "PostalAddress"}, location.City, countryCode(location.Country)}
+}
+
+func createGeoCoordinates(location events.Location) *ldGeoCoordinates {
+	if !location.HasGeo() {
+		return nil
+	}
+	return &ldGeoCoordinates{ldThing{Type: "GeoCoordinates"}, location.Lat, location.Lon}
+}
+
+func absoluteUrl(baseUrl utils.Url, url string) string {
+	if strings.HasPrefix(url, "http:") || strings.HasPrefix(url, "https:") {
+		return url
+	}
+	return baseUrl.Join(strings.TrimPrefix(url, "/"))
+}
+
+func createBreadcrumbList(breadcrumbs utils.Breadcrumbs, baseUrl utils.Url) any {
+	if len(breadcrumbs) == 0 {
+		return nil
+	}
+	list := ldBreadcrumbList{ldThing{schemaOrg, "BreadcrumbList"}, make([]ldListItem, 0, len(breadcrumbs))}
+	for _, b := range breadcrumbs {
+		list.ItemListElement = append(list.ItemListElement, ldListItem{ldThing{Type: "ListItem"}, b.Position, b.Link.Name, absoluteUrl(baseUrl, b.Link.Url)})
+	}
+	return list
+}
+
+func registrationUrl(event *events.Event) string {
+	for _, link := range event.Links {
+		if link.IsRegistration() && link.IsExternal() {
+			return link.Url
+		}
+	}
+	return ""
+}
+
+func createSportsEvent(event *events.Event, url, description, image string) *ldSportsEvent {
+	if event.Time.IsZero() {
+		return nil
+	}
+
+	status := "https://schema.org/EventScheduled"
+	if event.Cancelled {
+		status = "https://schema.org/EventCancelled"
+	}
+
+	e := &ldSportsEvent{
+		ldThing:             ldThing{schemaOrg, "SportsEvent"},
+		Name:                event.Name.Orig,
+		Description:         description,
+		Url:                 url,
+		Image:               image,
+		StartDate:           event.Time.From.Format("2006-01-02"),
+		EndDate:             event.Time.To.Format("2006-01-02"),
+		EventStatus:         status,
+		EventAttendanceMode: "https://schema.org/OfflineEventAttendanceMode",
+		Location: ldPlace{
+			ldThing{Type: "Place"},
+			event.Location.NameNoFlag(),
+			createPostalAddress(event.Location),
+			createGeoCoordinates(event.Location),
+		},
+	}
+	if reg := registrationUrl(event); reg != "" {
+		e.Offers = &ldOffer{ldThing{Type: "Offer"}, reg}
+	}
+	if event.MainLink != nil && event.MainLink.IsExternal() {
+		e.Organizer = &ldOrganization{ldThing{Type: "Organization"}, event.MainLink.Name, event.MainLink.Url}
+	}
+	return e
+}
+
+func createLocalBusiness(event *events.Event, url, description, image string) *ldLocalBusiness {
+	t := "LocalBusiness"
+	if event.Type == "group" {
+		t = "SportsActivityLocation"
+	}
+	b := &ldLocalBusiness{
+		ldThing:     ldThing{schemaOrg, t},
+		Name:        event.Name.Orig,
+		Description: description,
+		Url:         url,
+		Image:       image,
+		Address:     createPostalAddress(event.Location),
+		Geo:         createGeoCoordinates(event.Location),
+	}
+	if event.MainLink != nil && event.MainLink.IsExternal() {
+		b.SameAs = event.MainLink.Url
+	}
+	return b
+}
+
+func createEventJsonLD(event *events.Event, url, description, image string) any {
+	switch event.Type {
+	case "event":
+		if e := createSportsEvent(event, url, description, image); e != nil {
+			return e
+		}
+	case "group", "shop":
+		return createLocalBusiness(event, url, description, image)
+	}
+	return nil
+}
+
+func marshalJsonLD(items ...any) template.JS {
+	objects := make([]any, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			objects = append(objects, item)
+		}
+	}
+	if len(objects) == 0 {
+		return ""
+	}
+
+	var data any = objects
+	if len(objects) == 1 {
+		data = objects[0]
+	}
+	buf, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("cannot marshal json-ld: %v", err)
+		return ""
+	}
+	return template.JS(buf)
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// required properties of the schema.org types we emit (as expected by search engines)
+var schemaOrgRequired = map[string][]string{
+	"BreadcrumbList":         {"itemListElement"},
+	"ListItem":               {"position", "name", "item"},
+	"SportsEvent":            {"name", "startDate", "endDate", "location", "eventStatus", "url"},
+	"Place":                  {"name", "address"},
+	"PostalAddress":          {"addressLocality", "addressCountry"},
+	"GeoCoordinates":         {"latitude", "longitude"},
+	"Offer":                  {"url"},
+	"Organization":           {"name", "url"},
+	"LocalBusiness":          {"name", "address", "url"},
+	"SportsActivityLocation": {"name", "address", "url"},
+}
+
+func validateSchemaOrg(t *testing.T, path string, v any) []string {
+	var types []string
+	switch value := v.(type) {
+	case []any:
+		for _, item := range value {
+			types = append(types, validateSchemaOrg(t, path+"[]", item)...)
+		}
+	case map[string]any:
+		typ, ok := value["@type"].(string)
+		if !ok {
+			t.Errorf("%s: missing @type", path)
+			return types
+		}
+		required, known := schemaOrgRequired[typ]
+		if !known {
+			t.Errorf("%s: unexpected @type %q", path, typ)
+		}
+		for _, prop := range required {
+			if p, found := value[prop]; !found || p == "" {
+				t.Errorf("%s: %s misses required property %q", path, typ, prop)
+			}
+		}
+		types = append(types, typ)
+		for key, prop := range value {
+			types = append(types, validateSchemaOrg(t, path+"."+key, prop)...)
+		}
+	}
+	return types
+}
+
+func parseJsonLD(t *testing.T, js string) any {
+	var v any
+	if err := json.Unmarshal([]byte(js), &v); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, js)
+	}
+	return v
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEventStructuredData(t *testing.T) {
+	timeRange, err := utils.CreateTimeRange("12.07.2025 - 13.07.2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := &events.Event{
+		Type:      "event",
+		Name:      utils.NewName("Test </script> Lauf"),
+		Time:      timeRange,
+		Cancelled: true,
+		Location:  events.CreateLocation("Wissembourg, FR", "49.03,7.94"),
+		MainLink:  utils.CreateUnnamedLink("https://example.com/"),
+		Links:     []*utils.Link{utils.CreateLink("Anmeldung", "https://example.com/reg")},
+	}
+	breadcrumbs := utils.InitBreadcrumbs(utils.CreateLink("heidelberg.run", "/")).Push(utils.CreateLink("Test", "/event/2025-test.html"))
+	data := EventTemplateData{TemplateData{CommonData: CommonData{BaseUrl: "https://heidelberg.run"}, Canonical: "https://heidelberg.run/event/2025-test.html", Breadcrumbs: breadcrumbs}, event}
+
+	js := string(data.StructuredData())
+	types := validateSchemaOrg(t, "$", parseJsonLD(t, js))
+	for _, expected := range []string{"SportsEvent", "Place", "PostalAddress", "GeoCoordinates", "Offer", "Organization", "BreadcrumbList", "ListItem"} {
+		if !contains(types, expected) {
+			t.Errorf("missing @type %q in %s", expected, js)
+		}
+	}
+
+	var objects []map[string]any
+	if err := json.Unmarshal([]byte(js), &objects); err != nil {
+		t.Fatal(err)
+	}
+	e := objects[0]
+	if e["startDate"] != "2025-07-12" || e["endDate"] != "2025-07-13" {
+		t.Errorf("bad dates: %v %v", e["startDate"], e["endDate"])
+	}
+	if e["eventStatus"] != "https://schema.org/EventCancelled" {
+		t.Errorf("bad eventStatus: %v", e["eventStatus"])
+	}
+	if country := e["location"].(map[string]any)["address"].(map[string]any)["addressCountry"]; country != "FR" {
+		t.Errorf("bad addressCountry: %v", country)
+	}
+	if item := objects[1]["itemListElement"].([]any)[1].(map[string]any)["item"]; item != "https://heidelberg.run/event/2025-test.html" {
+		t.Errorf("bad breadcrumb item: %v", item)
+	}
+}
+
+func TestGroupShopStructuredData(t *testing.T) {
+	for _, tc := range []struct {
+		eventType    string
+		expectedType string
+	}{
+		{"group", "SportsActivityLocation"},
+		{"shop", "LocalBusiness"},
+	} {
+		event := &events.Event{
+			Type:     tc.eventType,
+			Name:     utils.NewName("Test"),
+			Location: events.CreateLocation("Heidelberg", ""),
+			MainLink: utils.CreateUnnamedLink("https://example.com/"),
+		}
+		data := EventTemplateData{TemplateData{CommonData: CommonData{BaseUrl: "https://heidelberg.run"}, Canonical: "https://heidelberg.run/x.html"}, event}
+		types := validateSchemaOrg(t, tc.eventType, parseJsonLD(t, string(data.StructuredData())))
+		if !contains(types, tc.expectedType) {
+			t.Errorf("%s: missing @type %q", tc.eventType, tc.expectedType)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"sync"
+)
+
+// pool runs jobs on a bounded number of goroutines and keeps the first error.
+type pool struct {
+	slots chan struct{}
+	wg    sync.WaitGroup
+	mutex sync.Mutex
+	err   error
+}
+
+func newPool(workers int) *pool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &pool{slots: make(chan struct{}, workers)}
+}
+
+func (p *pool) failed() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.err != nil
+}
+
+// Go schedules job, blocking while all workers are busy; after the first error no further jobs are started.
+func (p *pool) Go(job func() error) {
+	p.slots <- struct{}{}
+	if p.failed() {
+		<-p.slots
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.slots
+			p.wg.Done()
+		}()
+		if err := job(); err != nil {
+			p.mutex.Lock()
+			if p.err == nil {
+				p.err = err
+			}
+			p.mutex.Unlock()
+		}
+	}()
+}
+
+// Wait waits for all scheduled jobs and returns the first error.
+func (p *pool) Wait() error {
+	p.wg.Wait()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.err
+}
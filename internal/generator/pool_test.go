@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPool(t *testing.T) {
+	var running, maxRunning, done atomic.Int32
+	workers := newPool(3)
+	for i := 0; i < 20; i++ {
+		workers.Go(func() error {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			running.Add(-1)
+			done.Add(1)
+			return nil
+		})
+	}
+	if err := workers.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if done.Load() != 20 {
+		t.Errorf("expected 20 finished jobs, got %d", done.Load())
+	}
+	if maxRunning.Load() > 3 {
+		t.Errorf("expected at most 3 parallel jobs, got %d", maxRunning.Load())
+	}
+}
+
+func TestPoolError(t *testing.T) {
+	workers := newPool(1)
+	for i := 0; i < 5; i++ {
+		workers.Go(func() error {
+			if i >= 2 {
+				return fmt.Errorf("job %d failed", i)
+			}
+			return nil
+		})
+	}
+	if err := workers.Wait(); err == nil || err.Error() != "job 2 failed" {
+		t.Errorf("expected error of job 2, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+// RedirectBackend selects the format the redirects (old slugs, obsolete entries, renamed pages) are written in.
+type RedirectBackend string
+
+const (
+	RedirectHtaccess RedirectBackend = "htaccess" // Apache '.htaccess'
+	RedirectHtml     RedirectBackend = "html"     // stub pages with meta refresh (GitHub Pages)
+	RedirectNetlify  RedirectBackend = "netlify"  // Netlify '_redirects'
+	RedirectNginx    RedirectBackend = "nginx"    // nginx 'map' in '.nginx-redirects.conf'
+)
+
+var DefaultRedirectBackends = []RedirectBackend{RedirectHtaccess}
+
+// LoadRedirectConfig reads the redirect backends from the "redirects" list of the config file.
+func LoadRedirectConfig(path string) ([]RedirectBackend, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load redirect config file '%s': %w", path, err)
+	}
+	var config struct {
+		Redirects []string `json:"redirects"`
+	}
+	if err := json.Unmarshal(buf, &config); err != nil {
+		return nil, fmt.Errorf("unmarshall redirect config data: %w", err)
+	}
+	return ParseRedirectBackends(config.Redirects)
+}
+
+func ParseRedirectBackends(names []string) ([]RedirectBackend, error) {
+	if len(names) == 0 {
+		return DefaultRedirectBackends, nil
+	}
+	backends := make([]RedirectBackend, 0, len(names))
+	for _, name := range names {
+		switch backend := RedirectBackend(name); backend {
+		case RedirectHtaccess, RedirectHtml, RedirectNetlify, RedirectNginx:
+			backends = append(backends, backend)
+		default:
+			return nil, fmt.Errorf("unknown redirect backend '%s'", name)
+		}
+	}
+	return backends, nil
+}
+
+// collectRedirects returns all redirects of the site; the first redirect of a path wins.
+func collectRedirects(data events.Data) []utils.Redirect {
+	redirects := make([]utils.Redirect, 0)
+	seen := make(map[string]struct{})
+	add := func(from string, to string) {
+		if _, ok := seen[from]; ok || from == to {
+			return
+		}
+		seen[from] = struct{}{}
+		redirects = append(redirects, utils.Redirect{From: from, To: to})
+	}
+
+	add("/parkrun", "/bahnstadtpromenade-parkrun.html")
+	add("/groups.html", "/lauftreffs.html")
+	add("/event/bahnstadtpromenade-parkrun.html", "/group/bahnstadtpromenade-parkrun.html")
+	add("/tag/2025.html", "/events-old.html")
+	add("/tag/2026.html", "/")
+
+	for _, eventList := range [][]*events.Event{data.Events, data.EventsOld} {
+		for _, e := range eventList {
+			slug := e.Slug()
+			if old := e.SlugOld(); old != "" {
+				add("/"+old, "/"+slug)
+			}
+			if slugNoBase := e.SlugNoBase(); slugNoBase != slug {
+				add("/"+slugNoBase, "/"+slug)
+			}
+		}
+	}
+	for _, eventList := range [][]*events.Event{data.Groups, data.Shops} {
+		for _, e := range eventList {
+			if old := e.SlugOld(); old != "" {
+				add("/"+old, "/"+e.Slug())
+			}
+		}
+	}
+
+	for _, r := range data.SlugRedirects {
+		add(r.From, r.To)
+	}
+
+	for _, e := range data.EventsObsolete {
+		add("/"+e.Slug(), "/")
+	}
+	for _, e := range data.GroupsObsolete {
+		add("/"+e.Slug(), "/lauftreffs.html")
+	}
+	for _, e := range data.ShopsObsolete {
+		add("/"+e.Slug(), "/shops.html")
+	}
+
+	return redirects
+}
+
+func createHtaccess(redirects []utils.Redirect, outDir utils.Path) error {
+	var destination strings.Builder
+	destination.WriteString("ErrorDocument 404 /404.html\n")
+	for _, r := range redirects {
+		destination.WriteString(fmt.Sprintf("Redirect %s %s\n", r.From, r.To))
+	}
+	_, err := utils.WriteFileIfChanged(outDir.Join(".htaccess"), []byte(destination.String()))
+	return err
+}
+
+func createNetlifyRedirects(redirects []utils.Redirect, outDir utils.Path) error {
+	var destination strings.Builder
+	for _, r := range redirects {
+		destination.WriteString(fmt.Sprintf("%s %s 301\n", r.From, r.To))
+	}
+	// Netlify serves /404.html for missing pages by itself
+	_, err := utils.WriteFileIfChanged(outDir.Join("_redirects"), []byte(destination.String()))
+	return err
+}
+
+func createNginxMap(redirects []utils.Redirect, outDir utils.Path) error {
+	var destination strings.Builder
+	destination.WriteString("# include in the http block; in the server block add:\n")
+	destination.WriteString("#   if ($redirect_target) { return 301 $redirect_target; }\n")
+	destination.WriteString("#   error_page 404 /404.html;\n")
+	destination.WriteString("map $uri $redirect_target {\n")
+	destination.WriteString("    default \"\";\n")
+	for _, r := range redirects {
+		destination.WriteString(fmt.Sprintf("    %s %s;\n", r.From, r.To))
+	}
+	destination.WriteString("}\n")
+	_, err := utils.WriteFileIfChanged(outDir.Join(".nginx-redirects.conf"), []byte(destination.String()))
+	return err
+}
+
+var redirectStub = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html lang="de">
+<head>
+<meta charset="utf-8">
+<title>Weiterleitung</title>
+<link rel="canonical" href="{{.}}">
+<meta http-equiv="refresh" content="0; url={{.}}">
+</head>
+<body>
+<p>Diese Seite ist umgezogen: <a href="{{.}}">{{.}}</a></p>
+</body>
+</html>
+`))
+
+// createRedirectStubs writes a stub page with a meta refresh for every redirect, for hosts ignoring .htaccess (GitHub Pages).
+// Paths without extension get an index.html; pages rendered in this run are never overwritten.
+func createRedirectStubs(redirects []utils.Redirect, outDir utils.Path, baseUrl utils.Url) error {
+	for _, r := range redirects {
+		fileName := outDir.Join(strings.TrimPrefix(r.From, "/"))
+		if path.Ext(r.From) == "" {
+			fileName = outDir.Join(strings.TrimPrefix(r.From, "/"), "index.html")
+		}
+		if _, rendered := utils.LookupPageHash(fileName); rendered {
+			continue
+		}
+
+		target := r.To
+		if strings.HasPrefix(target, "/") {
+			target = baseUrl.Join(strings.TrimPrefix(target, "/"))
+		}
+		var buffer bytes.Buffer
+		if err := redirectStub.Execute(&buffer, target); err != nil {
+			return fmt.Errorf("render redirect stub for '%s': %w", r.From, err)
+		}
+		if _, err := utils.WriteFileIfChanged(fileName, buffer.Bytes()); err != nil {
+			return fmt.Errorf("write redirect stub for '%s': %w", r.From, err)
+		}
+	}
+	return nil
+}
+
+func (g Generator) createRedirects(data events.Data) error {
+	if err := utils.MakeDir(g.out.String()); err != nil {
+		return err
+	}
+
+	redirects := collectRedirects(data)
+	for _, backend := range g.redirects {
+		var err error
+		switch backend {
+		case RedirectHtaccess:
+			err = createHtaccess(redirects, g.out)
+		case RedirectHtml:
+			err = createRedirectStubs(redirects, g.out, g.baseUrl)
+		case RedirectNetlify:
+			err = createNetlifyRedirects(redirects, g.out)
+		case RedirectNginx:
+			err = createNginxMap(redirects, g.out)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", backend, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestRedirects(t *testing.T) {
+	timeRange, err := utils.CreateTimeRange("17.05.2026")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := events.Data{
+		Groups: []*events.Event{
+			{Type: "group", Name: utils.NewName("Lauftreff Neu"), NameOld: utils.NewName("Lauftreff Alt")},
+		},
+		EventsObsolete: []*events.Event{
+			{Type: "event", Name: utils.NewName("Stadtlauf"), Time: timeRange},
+			{Type: "event", Name: utils.NewName("Stadtlauf"), Time: timeRange},
+		},
+	}
+	redirects := collectRedirects(data)
+	found := 0
+	for _, r := range redirects {
+		switch r.From {
+		case "/group/lauftreff-alt.html":
+			found += 1
+			if r.To != "/group/lauftreff-neu.html" {
+				t.Errorf("unexpected target %s", r.To)
+			}
+		case "/event/2026-stadtlauf.html":
+			found += 1
+		}
+	}
+	if found != 2 {
+		t.Errorf("expected one redirect for the old group slug and one for the obsolete event, got %v", redirects)
+	}
+
+	out := utils.NewPath(t.TempDir())
+	g := Generator{out: out, baseUrl: "https://heidelberg.run", redirects: []RedirectBackend{RedirectHtml, RedirectNetlify, RedirectNginx}}
+	if err := g.createRedirects(data); err != nil {
+		t.Fatal(err)
+	}
+
+	stub, err := os.ReadFile(out.Join("group", "lauftreff-alt.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(stub), `<link rel="canonical" href="https://heidelberg.run/group/lauftreff-neu.html">`) {
+		t.Errorf("stub misses canonical link:\n%s", stub)
+	}
+	if _, err := os.Stat(out.Join("parkrun", "index.html")); err != nil {
+		t.Errorf("expected index.html stub for path without extension: %v", err)
+	}
+
+	netlify, err := os.ReadFile(out.Join("_redirects"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(netlify), "/group/lauftreff-alt.html /group/lauftreff-neu.html 301\n") {
+		t.Errorf("unexpected _redirects:\n%s", netlify)
+	}
+
+	nginx, err := os.ReadFile(out.Join(".nginx-redirects.conf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(nginx), "    /group/lauftreff-alt.html /group/lauftreff-neu.html;\n") {
+		t.Errorf("unexpected nginx map:\n%s", nginx)
+	}
+	if _, err := os.Stat(out.Join(".htaccess")); err == nil {
+		t.Errorf("unexpected .htaccess")
+	}
+
+	if _, err := ParseRedirectBackends([]string{"html", "iis"}); err == nil {
+		t.Errorf("expected error for unknown backend")
+	}
+}
@@ -0,0 +1,50 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
+	"github.com/svengiegerich/heidelberg-run/internal/stats"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+type StatsTemplateData struct {
+	TemplateData
+	Stats  *stats.Stats
+	Labels stats.Labels
+}
+
+// statsLabels returns the texts of the statistics charts in the language of the locale.
+func statsLabels(locale i18n.Locale) stats.Labels {
+	labels := stats.Labels{
+		Day:                  locale.FormatDay,
+		YearTitle:            locale.T("stats.chart.years"),
+		MonthTitle:           locale.T("stats.chart.months"),
+		MonthYearTitle:       locale.T("stats.chart.months-years"),
+		TagTitle:             locale.T("stats.chart.tags"),
+		DistanceTitle:        locale.T("stats.chart.distances"),
+		NewDiscontinuedTitle: locale.T("stats.chart.new-discontinued"),
+		Road:                 locale.T("stats.chart.road"),
+		Trail:                locale.T("stats.chart.trail"),
+		Events:               locale.T("stats.chart.events"),
+		New:                  locale.T("stats.chart.new"),
+		Discontinued:         locale.T("stats.chart.discontinued"),
+	}
+	for month := time.January; month <= time.December; month++ {
+		labels.Months[month-1] = string([]rune(locale.Month(month))[:3])
+	}
+	return labels
+}
+
+// writeStatsCharts writes the charts of the statistics page of the locale as standalone SVG files.
+func writeStatsCharts(s *stats.Stats, labels stats.Labels, out utils.Path, locale i18n.Locale) error {
+	for _, chart := range s.Charts(labels) {
+		fileName := out.Join(locale.Path(chart.File()))
+		buf := []byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" + string(chart.SVG))
+		if _, err := utils.WriteFileIfChanged(fileName, buf); err != nil {
+			return fmt.Errorf("write chart %q: %w", locale.Path(chart.File()), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type Locale string
+
+const (
+	DE Locale = "de"
+	EN Locale = "en"
+
+	// Default is the locale of the pages at the site root; all other locales live below /<locale>/.
+	Default = DE
+)
+
+// Locales lists all supported locales, the default locale first.
+var Locales = []Locale{DE, EN}
+
+//go:embed locales/*.json
+var catalogFiles embed.FS
+
+var catalog = loadCatalog()
+
+func loadCatalog() map[Locale]map[string]string {
+	c := make(map[Locale]map[string]string)
+	for _, locale := range Locales {
+		buf, err := catalogFiles.ReadFile(fmt.Sprintf("locales/%s.json", locale))
+		if err != nil {
+			panic(fmt.Sprintf("load message catalog for '%s': %v", locale, err))
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(buf, &messages); err != nil {
+			panic(fmt.Sprintf("parse message catalog for '%s': %v", locale, err))
+		}
+		c[locale] = messages
+	}
+	return c
+}
+
+func Parse(s string) (Locale, error) {
+	for _, locale := range Locales {
+		if string(locale) == s {
+			return locale, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported locale '%s'", s)
+}
+
+func (l Locale) IsDefault() bool {
+	return l == Default
+}
+
+// T returns the message for key (formatted with args, if any); missing messages fall back to the default locale.
+func (l Locale) T(key string, args ...any) string {
+	message, ok := catalog[l][key]
+	if !ok {
+		if message, ok = catalog[Default][key]; !ok {
+			log.Printf("i18n: missing message '%s'", key)
+			message = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Path returns the site-relative path of slug in the locale's output tree.
+func (l Locale) Path(slug string) string {
+	if l.IsDefault() {
+		return slug
+	}
+	return string(l) + "/" + strings.TrimPrefix(slug, "/")
+}
+
+// StripPath removes the locale prefix (as added by Path) from a site-relative path.
+func (l Locale) StripPath(path string) string {
+	if l.IsDefault() {
+		return path
+	}
+	if path == string(l) {
+		return ""
+	}
+	return strings.TrimPrefix(path, string(l)+"/")
+}
+
+func (l Locale) Weekday(d time.Weekday) string {
+	return l.T(fmt.Sprintf("weekday.%d", d))
+}
+
+func (l Locale) Month(m time.Month) string {
+	return l.T(fmt.Sprintf("month.%d", m))
+}
+
+// Direction returns the name of a compass direction (N, NE, E, ...).
+func (l Locale) Direction(code string) string {
+	return l.T("direction." + code)
+}
+
+// FormatDate formats a single date including the weekday, e.g. "Samstag, 15.11.2025" or "Saturday, 15 November 2025".
+func (l Locale) FormatDate(t time.Time) string {
+	if l == EN {
+		return fmt.Sprintf("%s, %d %s %d", l.Weekday(t.Weekday()), t.Day(), l.Month(t.Month()), t.Year())
+	}
+	return fmt.Sprintf("%s, %s", l.Weekday(t.Weekday()), t.Format("02.01.2006"))
+}
+
+// FormatDay formats a date without the weekday, e.g. "15.11.2025" or "15 November 2025"; without the year e.g.
+// "15.11." or "15 November".
+func (l Locale) FormatDay(t time.Time, withYear bool) string {
+	if l == EN {
+		if withYear {
+			return fmt.Sprintf("%d %s %d", t.Day(), l.Month(t.Month()), t.Year())
+		}
+		return fmt.Sprintf("%d %s", t.Day(), l.Month(t.Month()))
+	}
+	if withYear {
+		return t.Format("02.01.2006")
+	}
+	return t.Format("02.01.")
+}
+
+// FormatMonth formats a month, e.g. "Oktober 2025".
+func (l Locale) FormatMonth(t time.Time) string {
+	return fmt.Sprintf("%s %d", l.Month(t.Month()), t.Year())
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCatalogsComplete(t *testing.T) {
+	for _, locale := range Locales {
+		for key := range catalog[Default] {
+			if _, ok := catalog[locale][key]; !ok {
+				t.Errorf("%s: missing message '%s'", locale, key)
+			}
+		}
+		for key := range catalog[locale] {
+			if _, ok := catalog[Default][key]; !ok {
+				t.Errorf("%s: unknown message '%s'", locale, key)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := EN.T("month.3"); got != "March" {
+		t.Errorf("EN month.3: expected 'March', got '%s'", got)
+	}
+	if got := DE.T("month.3"); got != "März" {
+		t.Errorf("DE month.3: expected 'März', got '%s'", got)
+	}
+	if got := EN.T("no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key: expected key, got '%s'", got)
+	}
+}
+
+func TestPath(t *testing.T) {
+	testCases := []struct {
+		locale   Locale
+		slug     string
+		expected string
+	}{
+		{DE, "tags.html", "tags.html"},
+		{EN, "tags.html", "en/tags.html"},
+		{EN, "/group/x.html", "en/group/x.html"},
+	}
+	for _, tc := range testCases {
+		path := tc.locale.Path(tc.slug)
+		if path != tc.expected {
+			t.Errorf("%s.Path(%q): expected %q, got %q", tc.locale, tc.slug, tc.expected, path)
+		}
+		if tc.slug[0] != '/' && tc.locale.StripPath(path) != tc.slug {
+			t.Errorf("%s.StripPath(%q): expected %q, got %q", tc.locale, path, tc.slug, tc.locale.StripPath(path))
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	d := time.Date(2025, time.November, 15, 0, 0, 0, 0, time.UTC)
+	if got := DE.FormatDate(d); got != "Samstag, 15.11.2025" {
+		t.Errorf("DE: got '%s'", got)
+	}
+	if got := EN.FormatDate(d); got != "Saturday, 15 November 2025" {
+		t.Errorf("EN: got '%s'", got)
+	}
+	if got := DE.FormatDay(d, false); got != "15.11." {
+		t.Errorf("DE day: got '%s'", got)
+	}
+	if got := EN.FormatDay(d, true); got != "15 November 2025" {
+		t.Errorf("EN day: got '%s'", got)
+	}
+}
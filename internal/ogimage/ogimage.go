@@ -0,0 +1,248 @@
+package ogimage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	Width  = 1200
+	Height = 630
+
+	// renderVersion is part of the cache key; bump it when changing the layout
+	renderVersion = "1"
+
+	margin   = 60
+	logoSize = 120
+)
+
+var (
+	colorBackground = color.RGBA{0x44, 0x55, 0xf6, 0xff}
+	colorText       = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorTextLight  = color.RGBA{0xdd, 0xe1, 0xff, 0xff}
+	colorBadge      = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	colorBadgeText  = color.RGBA{0x44, 0x55, 0xf6, 0xff}
+)
+
+// Card holds the content of a share image.
+type Card struct {
+	Title string
+	Date  string
+	Place string
+	Badge string
+}
+
+func (c Card) Hash() string {
+	h := sha256.New()
+	for _, s := range []string{renderVersion, c.Title, c.Date, c.Place, c.Badge} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%.8x", h.Sum(nil))
+}
+
+// Renderer is safe for concurrent use.
+type Renderer struct {
+	cacheDir  string
+	drawMutex sync.Mutex // the font faces must not be used concurrently
+	cardLocks sync.Map   // card hash -> *sync.Mutex
+	logo      image.Image
+	titleFace font.Face
+	textFace  font.Face
+	badgeFace font.Face
+	siteFace  font.Face
+}
+
+func loadFace(ttf []byte, size float64) (font.Face, error) {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, err
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+func NewRenderer(logoPath string, cacheDir string) (*Renderer, error) {
+	f, err := os.Open(logoPath)
+	if err != nil {
+		return nil, fmt.Errorf("open logo: %w", err)
+	}
+	defer f.Close()
+	logo, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode logo %s: %w", logoPath, err)
+	}
+
+	r := &Renderer{cacheDir: cacheDir, logo: logo}
+	faces := []struct {
+		dest *font.Face
+		ttf  []byte
+		size float64
+	}{
+		{&r.titleFace, gobold.TTF, 60},
+		{&r.textFace, goregular.TTF, 40},
+		{&r.badgeFace, gobold.TTF, 32},
+		{&r.siteFace, gobold.TTF, 36},
+	}
+	for _, face := range faces {
+		if *face.dest, err = loadFace(face.ttf, face.size); err != nil {
+			return nil, fmt.Errorf("load font: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// wrap splits text into at most maxLines lines that fit into width; the last line is ellipsized if needed.
+func wrap(face font.Face, text string, width int, maxLines int) []string {
+	lines := make([]string, 0, maxLines)
+	line := ""
+	words := strings.Fields(text)
+	for i, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if font.MeasureString(face, candidate).Ceil() <= width || line == "" {
+			line = candidate
+			continue
+		}
+		if len(lines) == maxLines-1 {
+			return append(lines, ellipsize(face, strings.Join(append([]string{line}, words[i:]...), " "), width))
+		}
+		lines = append(lines, line)
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, ellipsize(face, line, width))
+	}
+	return lines
+}
+
+func ellipsize(face font.Face, text string, width int) string {
+	if font.MeasureString(face, text).Ceil() <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		s := strings.TrimSpace(string(runes)) + "…"
+		if font.MeasureString(face, s).Ceil() <= width {
+			return s
+		}
+	}
+	return ""
+}
+
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, text string) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+func fillRect(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+func (r *Renderer) draw(card Card) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, Width, Height))
+	fillRect(img, img.Bounds(), colorBackground)
+
+	// logo in the top left corner
+	logoRect := image.Rect(margin, margin, margin+logoSize, margin+logoSize)
+	draw.CatmullRom.Scale(img, logoRect, r.logo, r.logo.Bounds(), draw.Over, nil)
+
+	textWidth := Width - 2*margin
+	y := margin + logoSize + 80
+	for _, line := range wrap(r.titleFace, card.Title, textWidth, 2) {
+		drawText(img, r.titleFace, colorText, margin, y, line)
+		y += 72
+	}
+	y += 10
+	for _, line := range []string{card.Date, card.Place} {
+		if line == "" {
+			continue
+		}
+		drawText(img, r.textFace, colorTextLight, margin, y, ellipsize(r.textFace, line, textWidth))
+		y += 52
+	}
+
+	// tag badge in the bottom left corner
+	if card.Badge != "" {
+		badge := ellipsize(r.badgeFace, card.Badge, Width/2)
+		w := font.MeasureString(r.badgeFace, badge).Ceil()
+		badgeRect := image.Rect(margin, Height-margin-56, margin+w+40, Height-margin)
+		fillRect(img, badgeRect, colorBadge)
+		drawText(img, r.badgeFace, colorBadgeText, margin+20, Height-margin-16, badge)
+	}
+
+	// site name in the bottom right corner
+	site := "heidelberg.run"
+	w := font.MeasureString(r.siteFace, site).Ceil()
+	drawText(img, r.siteFace, colorText, Width-margin-w, Height-margin-14, site)
+
+	return img
+}
+
+func (r *Renderer) cached(card Card) (string, error) {
+	cacheFile := filepath.Join(r.cacheDir, card.Hash()+".png")
+	if _, err := os.Stat(cacheFile); err == nil {
+		return cacheFile, nil
+	}
+
+	r.drawMutex.Lock()
+	img := r.draw(card)
+	r.drawMutex.Unlock()
+
+	if err := utils.MakeDir(r.cacheDir); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(r.cacheDir, "tmp-*.png")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if err := png.Encode(tmp, img); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	return cacheFile, os.Rename(tmp.Name(), cacheFile)
+}
+
+// Render creates the share image for the card (or takes it from the cache) and copies it to the output directory.
+// It returns the path of the image relative to the output directory.
+func (r *Renderer) Render(card Card, out utils.Path) (string, error) {
+	lock, _ := r.cardLocks.LoadOrStore(card.Hash(), &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	cacheFile, err := r.cached(card)
+	if err != nil {
+		return "", fmt.Errorf("render share image for '%s': %w", card.Title, err)
+	}
+	slug := fmt.Sprintf("images/og/%s.png", card.Hash())
+	if err := utils.Copy(cacheFile, out.Join(slug)); err != nil {
+		return "", err
+	}
+	return slug, nil
+}
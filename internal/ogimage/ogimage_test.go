@@ -0,0 +1,69 @@
+package ogimage
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestRender(t *testing.T) {
+	cache := t.TempDir()
+	out := utils.NewPath(t.TempDir())
+
+	r, err := NewRenderer("../../static/512.png", cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	card := Card{"Heidelberger Halbmarathon mit einem sehr langen Namen, der umgebrochen werden muss", "Sonntag, 27.04.2025", "Heidelberg", "Straßenlauf"}
+	slug, err := r.Render(card, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slug != "images/og/"+card.Hash()+".png" {
+		t.Errorf("unexpected slug %q", slug)
+	}
+
+	f, err := os.Open(out.Join(slug))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != Width || b.Dy() != Height {
+		t.Errorf("image size = %dx%d; want %dx%d", b.Dx(), b.Dy(), Width, Height)
+	}
+
+	// a second render must reuse the cached file
+	cacheFile := filepath.Join(cache, card.Hash()+".png")
+	before, err := utils.GetMtime(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Render(card, out); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := utils.GetMtime(cacheFile)
+	if !before.Equal(after) {
+		t.Errorf("cached image was re-rendered")
+	}
+}
+
+func TestCardHash(t *testing.T) {
+	a := Card{"A", "B", "C", "D"}
+	if a.Hash() != (Card{"A", "B", "C", "D"}).Hash() {
+		t.Errorf("hash is not stable")
+	}
+	if a.Hash() == (Card{"A", "B", "C", "E"}).Hash() {
+		t.Errorf("hash ignores badge")
+	}
+	if a.Hash() == (Card{"AB", "", "C", "D"}).Hash() {
+		t.Errorf("hash does not separate fields")
+	}
+}
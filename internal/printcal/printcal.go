@@ -0,0 +1,159 @@
+package printcal
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+type Options struct {
+	Title    string
+	Subtitle string
+	PageSize string // "A4" or "A5"
+	BaseUrl  utils.Url
+	Now      time.Time
+}
+
+type layout struct {
+	margin      float64
+	titleSize   float64
+	monthSize   float64
+	nameSize    float64
+	textSize    float64
+	lineHeight  float64
+	qrSize      float64
+	monthHeight float64
+}
+
+func getLayout(pageSize string) (layout, error) {
+	switch strings.ToUpper(pageSize) {
+	case "A4":
+		return layout{15, 20, 13, 11, 9, 4.5, 18, 10}, nil
+	case "A5":
+		return layout{10, 15, 11, 9, 7.5, 3.6, 14, 8}, nil
+	default:
+		return layout{}, fmt.Errorf("unsupported page size '%s' (expected A4 or A5)", pageSize)
+	}
+}
+
+// Render writes a paginated PDF listing the events (including month separators as created by events.AddMonthSeparators) to fileName.
+func Render(eventList []*events.Event, options Options, fileName string) error {
+	l, err := getLayout(options.PageSize)
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("P", "mm", strings.ToUpper(options.PageSize), "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*l.margin
+	textWidth := contentWidth - l.qrSize - 4
+
+	pdf.SetMargins(l.margin, l.margin, l.margin)
+	pdf.SetAutoPageBreak(false, l.margin)
+	pdf.SetTitle(options.Title, true)
+	pdf.SetCreator("heidelberg.run", true)
+	pdf.AliasNbPages("{nb}")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(pageHeight - l.margin + 2)
+		pdf.SetFont("Helvetica", "", l.textSize-1)
+		pdf.SetTextColor(120, 120, 120)
+		footer := fmt.Sprintf("heidelberg.run - Stand: %s - Seite %d/{nb}", options.Now.Format("02.01.2006"), pdf.PageNo())
+		pdf.CellFormat(contentWidth, 4, tr(footer), "", 0, "C", false, 0, "")
+	})
+
+	bottom := pageHeight - l.margin
+	newPage := func() {
+		pdf.AddPage()
+		pdf.SetTextColor(0x44, 0x55, 0xf6)
+		pdf.SetFont("Helvetica", "B", l.titleSize)
+		pdf.CellFormat(contentWidth, l.titleSize*0.5, tr(options.Title), "", 1, "L", false, 0, "")
+		if options.Subtitle != "" {
+			pdf.SetTextColor(80, 80, 80)
+			pdf.SetFont("Helvetica", "", l.textSize)
+			pdf.CellFormat(contentWidth, l.lineHeight+1, tr(options.Subtitle), "", 1, "L", false, 0, "")
+		}
+		pdf.Ln(2)
+	}
+	newPage()
+
+	qrIndex := 0
+	for i, event := range eventList {
+		if event.IsSeparator() {
+			// skip month headings without events (e.g. empty months in between)
+			if i+1 == len(eventList) || eventList[i+1].IsSeparator() {
+				continue
+			}
+			// keep the month heading together with (at least) its first event
+			if pdf.GetY()+l.monthHeight+l.qrSize+2 > bottom {
+				newPage()
+			}
+			pdf.Ln(1)
+			pdf.SetFillColor(0xee, 0xf0, 0xfe)
+			pdf.SetTextColor(0x44, 0x55, 0xf6)
+			pdf.SetFont("Helvetica", "B", l.monthSize)
+			pdf.CellFormat(contentWidth, l.monthHeight-2, tr(event.Name.Orig), "", 1, "L", true, 0, "")
+			pdf.Ln(1)
+			continue
+		}
+
+		name := event.Name.Orig
+		if event.Cancelled {
+			name += " (abgesagt)"
+		}
+		place := event.Location.NameNoFlag()
+		if event.Location.HasGeo() {
+			place = fmt.Sprintf("%s (%s)", place, event.Location.Dir())
+		}
+
+		pdf.SetFont("Helvetica", "B", l.nameSize)
+		nameLines := pdf.SplitLines([]byte(tr(name)), textWidth)
+		rowHeight := float64(len(nameLines))*l.lineHeight + 2*l.lineHeight
+		if rowHeight < l.qrSize {
+			rowHeight = l.qrSize
+		}
+		if pdf.GetY()+rowHeight+2 > bottom {
+			newPage()
+		}
+
+		x, y := pdf.GetX(), pdf.GetY()
+		pdf.SetTextColor(0, 0, 0)
+		if event.Cancelled {
+			pdf.SetTextColor(200, 0, 0)
+		}
+		for _, line := range nameLines {
+			pdf.CellFormat(textWidth, l.lineHeight, string(line), "", 2, "L", false, 0, "")
+		}
+		pdf.SetTextColor(60, 60, 60)
+		pdf.SetFont("Helvetica", "", l.textSize)
+		pdf.CellFormat(textWidth, l.lineHeight, tr(event.Time.Formatted), "", 2, "L", false, 0, "")
+		pdf.CellFormat(textWidth, l.lineHeight, tr(place), "", 2, "L", false, 0, "")
+
+		url := options.BaseUrl.Join(event.Slug())
+		png, err := qrcode.Encode(url, qrcode.Medium, 256)
+		if err != nil {
+			return fmt.Errorf("create qr code for '%s': %w", url, err)
+		}
+		qrIndex++
+		qrName := fmt.Sprintf("qr%d", qrIndex)
+		opts := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(qrName, opts, bytes.NewReader(png))
+		pdf.ImageOptions(qrName, x+contentWidth-l.qrSize, y, l.qrSize, l.qrSize, false, opts, 0, url)
+
+		pdf.SetXY(x, y+rowHeight)
+		pdf.SetDrawColor(220, 220, 220)
+		pdf.Line(x, y+rowHeight+1, x+contentWidth, y+rowHeight+1)
+		pdf.SetY(y + rowHeight + 2)
+	}
+
+	if err := pdf.OutputFileAndClose(fileName); err != nil {
+		return fmt.Errorf("write pdf to %s: %w", fileName, err)
+	}
+	return nil
+}
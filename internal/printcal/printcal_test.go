@@ -0,0 +1,50 @@
+package printcal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestRender(t *testing.T) {
+	eventList := make([]*events.Event, 0)
+	for i := 1; i <= 40; i++ {
+		timeRange, err := utils.CreateTimeRange(fmt.Sprintf("%02d.%02d.2025", 1+i%28, 1+i/4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		eventList = append(eventList, &events.Event{
+			Type:      "event",
+			Name:      utils.NewName(fmt.Sprintf("Lauf Nummer %d über Stock und Stein", i)),
+			Time:      timeRange,
+			Cancelled: i%10 == 0,
+			Location:  events.CreateLocation("Schwetzingen", "49.38,8.57"),
+		})
+	}
+	eventList = events.AddMonthSeparators(eventList)
+
+	for _, size := range []string{"A4", "A5"} {
+		fileName := filepath.Join(t.TempDir(), "calendar.pdf")
+		err := Render(eventList, Options{"Laufkalender 2025", "Test", size, utils.Url("https://heidelberg.run"), time.Now()}, fileName)
+		if err != nil {
+			t.Fatalf("%s: %v", size, err)
+		}
+		buf, err := os.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(buf, []byte("%PDF-")) {
+			t.Errorf("%s: output is not a pdf", size)
+		}
+	}
+
+	if err := Render(eventList, Options{PageSize: "Letter"}, filepath.Join(t.TempDir(), "x.pdf")); err == nil {
+		t.Errorf("expected error for unsupported page size")
+	}
+}
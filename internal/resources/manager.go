@@ -1,26 +1,71 @@
 package resources
 
 import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/svengiegerich/heidelberg-run/internal/utils"
+	"github.com/tdewolff/minify/v2"
+	"github.com/tdewolff/minify/v2/css"
+	"github.com/tdewolff/minify/v2/js"
 )
 
+// ManifestEntry describes a single asset: Source is relative to the source dir, Target to the output dir; "HASH" in
+// Target is replaced by the content hash. Assets of Type "js" or "css" are included in every page (in manifest order).
+type ManifestEntry struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type,omitempty"`   // "js", "css" or "" (not included automatically)
+	Name   string `json:"name,omitempty"`   // name for the 'asset' template function (default: base name of Source)
+	Minify bool   `json:"minify,omitempty"` // minify JS/CSS before hashing
+}
+
+type Manifest struct {
+	Assets []ManifestEntry `json:"assets"`
+}
+
+func LoadManifest(path string) (Manifest, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("load asset manifest '%s': %w", path, err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(buf, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("unmarshall asset manifest: %w", err)
+	}
+	for _, entry := range manifest.Assets {
+		if entry.Source == "" || entry.Target == "" {
+			return Manifest{}, fmt.Errorf("asset manifest: entry without source or target: %+v", entry)
+		}
+		if entry.Type != "" && entry.Type != "js" && entry.Type != "css" {
+			return Manifest{}, fmt.Errorf("asset manifest: bad type '%s' of '%s'", entry.Type, entry.Source)
+		}
+	}
+	return manifest, nil
+}
+
 type ResourceManager struct {
-	SourceDir   string
-	TargetDir   string
-	JsFiles     []string
-	CssFiles    []string
-	UmamiScript string
-	Error       error
+	SourceDir string
+	TargetDir string
+	JsFiles   []utils.Asset
+	CssFiles  []utils.Asset
+	Assets    map[string]utils.Asset // hashed assets by name
+	Error     error
 }
 
 func NewResourceManager(sourceDir string, out string) *ResourceManager {
 	return &ResourceManager{
 		SourceDir: sourceDir,
 		TargetDir: out,
-		JsFiles:   make([]string, 0),
-		CssFiles:  make([]string, 0),
+		JsFiles:   make([]utils.Asset, 0),
+		CssFiles:  make([]utils.Asset, 0),
+		Assets:    make(map[string]utils.Asset),
 	}
 }
 
@@ -32,85 +77,84 @@ func (r *ResourceManager) DownloadErr(url, targetFile string) {
 	}
 }
 
-func (r *ResourceManager) CopyHashErr(sourcePath, targetFile string) string {
-	res, err := utils.CopyHash(sourcePath, filepath.Join(r.TargetDir, targetFile))
+// Integrity returns the Subresource Integrity value of buf.
+func Integrity(buf []byte) string {
+	sum := sha512.Sum384(buf)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func minifyAsset(buf []byte, ext string) ([]byte, error) {
+	m := minify.New()
+	m.AddFunc("text/css", css.Minify)
+	m.AddFunc("application/javascript", js.Minify)
+
+	var mediaType string
+	switch ext {
+	case ".js":
+		mediaType = "application/javascript"
+	case ".css":
+		mediaType = "text/css"
+	default:
+		return nil, fmt.Errorf("cannot minify '%s' files", ext)
+	}
+	var minified bytes.Buffer
+	if err := m.Minify(mediaType, &minified, bytes.NewReader(buf)); err != nil {
+		return nil, err
+	}
+	return minified.Bytes(), nil
+}
+
+func (r *ResourceManager) copyAsset(entry ManifestEntry) error {
+	buf, err := os.ReadFile(filepath.Join(r.SourceDir, entry.Source))
 	if err != nil {
-		r.Error = err
-		return ""
+		return fmt.Errorf("read asset: %w", err)
+	}
+	if entry.Minify {
+		if buf, err = minifyAsset(buf, filepath.Ext(entry.Source)); err != nil {
+			return fmt.Errorf("minify %s: %w", entry.Source, err)
+		}
 	}
 
-	rel, err := filepath.Rel(r.TargetDir, res)
+	target, err := utils.WriteHash(filepath.Join(r.TargetDir, entry.Target), buf)
 	if err != nil {
-		r.Error = err
-		return ""
+		return err
+	}
+	if !strings.Contains(entry.Target, "HASH") && entry.Type == "" {
+		// plain copy (robots.txt, icons, ...)
+		return nil
 	}
 
-	return rel
-}
+	rel, err := filepath.Rel(r.TargetDir, target)
+	if err != nil {
+		return err
+	}
+	asset := utils.Asset{Path: filepath.ToSlash(rel), Integrity: Integrity(buf)}
+	name := entry.Name
+	if name == "" {
+		name = filepath.Base(entry.Source)
+	}
+	if _, found := r.Assets[name]; found {
+		return fmt.Errorf("duplicate asset name '%s'", name)
+	}
+	r.Assets[name] = asset
 
-func (r *ResourceManager) CopyExternalAssets() {
-	source := utils.Path(r.SourceDir)
-	static := utils.Path(source.Join("static"))
-	vendor := utils.Path(source.Join("external-files"))
-
-	// JS files
-	r.JsFiles = append(r.JsFiles, r.CopyHashErr(vendor.Join("leaflet", "leaflet.js"), "leaflet-HASH.js"))
-	r.JsFiles = append(r.JsFiles, r.CopyHashErr(vendor.Join("leaflet-legend", "leaflet-legend.js"), "leaflet-legend-HASH.js"))
-	r.JsFiles = append(r.JsFiles, r.CopyHashErr(vendor.Join("leaflet-gesture-handling", "leaflet-gesture-handling.js"), "leaflet-gesture-handling-HASH.js"))
-	r.JsFiles = append(r.JsFiles, r.CopyHashErr(static.Join("parkrun-track.js"), "parkrun-track-HASH.js"))
-	r.JsFiles = append(r.JsFiles, r.CopyHashErr(static.Join("main.js"), "main-HASH.js"))
-
-	r.UmamiScript = r.CopyHashErr(vendor.Join("umami", "umami.js"), "umami-HASH.js")
-
-	// CSS files
-	r.CssFiles = append(r.CssFiles, r.CopyHashErr(vendor.Join("bulma", "bulma.css"), "bulma-HASH.css"))
-	r.CssFiles = append(r.CssFiles, r.CopyHashErr(vendor.Join("leaflet", "leaflet.css"), "leaflet-HASH.css"))
-	r.CssFiles = append(r.CssFiles, r.CopyHashErr(vendor.Join("leaflet-legend", "leaflet-legend.css"), "leaflet-legend-HASH.css"))
-	r.CssFiles = append(r.CssFiles, r.CopyHashErr(vendor.Join("leaflet-gesture-handling", "leaflet-gesture-handling.css"), "leaflet-gesture-handling-HASH.css"))
-	r.CssFiles = append(r.CssFiles, r.CopyHashErr(static.Join("style.css"), "style-HASH.css"))
-
-	// Images
-	r.CopyHashErr(vendor.Join("leaflet", "marker-icon.png"), filepath.Join(r.TargetDir, "images/marker-icon.png"))
-	r.CopyHashErr(vendor.Join("leaflet", "marker-icon-2x.png"), filepath.Join(r.TargetDir, "images/marker-icon-2x.png"))
-	r.CopyHashErr(vendor.Join("leaflet", "marker-shadow.png"), filepath.Join(r.TargetDir, "images/marker-shadow.png"))
+	switch entry.Type {
+	case "js":
+		r.JsFiles = append(r.JsFiles, asset)
+	case "css":
+		r.CssFiles = append(r.CssFiles, asset)
+	}
+	return nil
 }
 
-func (r *ResourceManager) CopyStaticAssets() {
-	// Copy static files using a slice of pairs to handle duplicate source files
-	staticFiles := []struct {
-		Source      string
-		Destination string
-	}{
-		{"static/robots.txt", "robots.txt"},
-		{"static/manifest.json", "manifest.json"},
-		{"static/512.png", "favicon.png"},
-		{"static/favicon.ico", "favicon.ico"},
-		{"static/180.png", "apple-touch-icon.png"},
-		{"static/192.png", "android-chrome-192x192.png"},
-		{"static/512.png", "android-chrome-512x512.png"},
-		{"static/heidelberg-run.svg", "images/heidelberg-run.svg"},
-		{"static/heidelberg-run.svg", "images/heidelberg-run.svg"},
-		{"static/heidelberg-run-blue.svg", "images/heidelberg-run-blue.svg"},
-		{"static/512.png", "images/512.png"},
-		{"static/marker-icon.png", "images/marker-icon.png"},
-		{"static/marker-icon-2x.png", "images/marker-icon-2x.png"},
-		{"static/marker-grey-icon.png", "images/marker-grey-icon.png"},
-		{"static/marker-grey-icon-2x.png", "images/marker-grey-icon-2x.png"},
-		{"static/marker-green-icon.png", "images/marker-green-icon.png"},
-		{"static/marker-green-icon-2x.png", "images/marker-green-icon-2x.png"},
-		{"static/marker-red-icon.png", "images/marker-red-icon.png"},
-		{"static/marker-red-icon-2x.png", "images/marker-red-icon-2x.png"},
-		{"static/circle-small.png", "images/circle-small.png"},
-		{"static/circle-big.png", "images/circle-big.png"},
-	}
-
-	source := utils.Path(r.SourceDir)
-	target := utils.Path(r.TargetDir)
-
-	for _, file := range staticFiles {
-		if err := utils.Copy(source.Join(file.Source), target.Join(file.Destination)); err != nil {
-			r.Error = err
+// CopyAssets copies (and minifies) the assets of manifest to the target dir and registers them for the 'asset'
+// template function.
+func (r *ResourceManager) CopyAssets(manifest Manifest) {
+	for _, entry := range manifest.Assets {
+		if err := r.copyAsset(entry); err != nil {
+			r.Error = fmt.Errorf("asset '%s': %w", entry.Source, err)
 			return
 		}
 	}
+	utils.RegisterAssets(r.Assets)
 }
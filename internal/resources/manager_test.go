@@ -1,35 +1,66 @@
 package resources
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
 )
 
-func TestCopyExternalAssets(t *testing.T) {
+func TestCopyAssets(t *testing.T) {
 	// Create temp dir
 	out := t.TempDir()
 
+	manifest, err := LoadManifest("../../assets.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	// Create resource manager
-	rm := NewResourceManager("../../", out)
+	rm := NewResourceManager("../..", out)
 
-	// Copy external assets
-	rm.CopyExternalAssets()
+	// Copy assets
+	rm.CopyAssets(manifest)
 
 	if rm.Error != nil {
-		t.Fatalf("failed to copy external assets: %v", rm.Error)
+		t.Fatalf("failed to copy assets: %v", rm.Error)
+	}
+	if len(rm.JsFiles) != 5 || len(rm.CssFiles) != 5 {
+		t.Errorf("expected 5 JS and 5 CSS files, got %v and %v", rm.JsFiles, rm.CssFiles)
 	}
-}
-
-func TestCopyStaticAssets(t *testing.T) {
-	// Create temp dir
-	out := t.TempDir()
 
-	// Create resource manager
-	rm := NewResourceManager("../..", out)
+	main, err := utils.LookupAsset("main.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(filepath.Join(out, main.Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if main.Integrity != Integrity(buf) {
+		t.Errorf("integrity %s does not match the content of %s", main.Integrity, main.Path)
+	}
+	source, err := os.ReadFile("../../static/main.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) >= len(source) {
+		t.Errorf("expected minified main.js (%d bytes, source: %d bytes)", len(buf), len(source))
+	}
 
-	// Copy static assets
-	rm.CopyStaticAssets()
+	if _, err := os.Stat(filepath.Join(out, "robots.txt")); err != nil {
+		t.Errorf("static file not copied: %v", err)
+	}
+	if _, err := utils.LookupAsset("robots.txt"); err == nil {
+		t.Errorf("unexpected asset for plain copy")
+	}
+}
 
-	if rm.Error != nil {
-		t.Fatalf("failed to copy static assets: %v", rm.Error)
+func TestIntegrity(t *testing.T) {
+	// echo -n "alert('Hello, world.');" | openssl dgst -sha384 -binary | openssl base64 -A
+	expected := "sha384-H8BRh8j48O9oYatfu5AZzq6A9RINhZO5H16dQZngK7T62em8MUt1FLm52t+eX6xO"
+	if integrity := Integrity([]byte("alert('Hello, world.');")); integrity != expected {
+		t.Errorf("expected %s, got %s", expected, integrity)
 	}
 }
@@ -0,0 +1,196 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+const Version = 1
+
+// Document is a single search result; the short JSON keys keep the index small.
+type Document struct {
+	Name  string `json:"n"`
+	Slug  string `json:"u"`           // site-relative path of the page (without locale prefix)
+	Type  string `json:"t"`           // "event", "group" or "shop"
+	Date  string `json:"d,omitempty"` // original date string of events
+	Place string `json:"p,omitempty"` // city (and country if not Germany)
+}
+
+// Index maps tokens to the (ascending) positions of the documents they occur in.
+type Index struct {
+	Version   int              `json:"v"`
+	StopWords []string         `json:"stop"` // to be dropped from queries, too
+	Docs      []Document       `json:"docs"`
+	Tokens    map[string][]int `json:"tokens"`
+}
+
+// stopWords are frequent German and English words that are not worth indexing.
+var stopWords = map[string]struct{}{
+	"am": {}, "an": {}, "auf": {}, "aus": {}, "bei": {}, "das": {}, "dem": {}, "den": {}, "der": {}, "des": {}, "die": {},
+	"ein": {}, "eine": {}, "einen": {}, "einem": {}, "einer": {}, "fuer": {}, "im": {}, "in": {}, "ist": {}, "mit": {},
+	"nach": {}, "oder": {}, "sich": {}, "sie": {}, "um": {}, "und": {}, "vom": {}, "von": {}, "wir": {}, "zu": {}, "zum": {}, "zur": {},
+	"and": {}, "at": {}, "for": {}, "of": {}, "on": {}, "or": {}, "the": {}, "to": {},
+}
+
+// Tokenize splits s into lowercase ASCII tokens, folding umlauts and accents the same way as utils.SanitizeName.
+// Single characters and stop words are dropped.
+func Tokenize(s string) []string {
+	tokens := make([]string, 0)
+	for _, token := range strings.Split(utils.SanitizeName(s), "-") {
+		if len(token) < 2 {
+			continue
+		}
+		if _, stop := stopWords[token]; stop {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+func stripHtml(s string) string {
+	return html.UnescapeString(htmlTag.ReplaceAllString(s, " "))
+}
+
+func place(location events.Location) string {
+	if location.Country != "" {
+		return fmt.Sprintf("%s, %s", location.City, location.Country)
+	}
+	return location.City
+}
+
+// dateTerms returns the names (in all locales) of the months and the years covered by the time range.
+func dateTerms(t utils.TimeRange) []string {
+	if t.From.IsZero() {
+		return nil
+	}
+	to := t.To
+	if to.IsZero() || to.Before(t.From) {
+		to = t.From
+	}
+	terms := make([]string, 0)
+	for m := time.Date(t.From.Year(), t.From.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(to); m = m.AddDate(0, 1, 0) {
+		for _, locale := range i18n.Locales {
+			terms = append(terms, locale.Month(m.Month()))
+		}
+		terms = append(terms, fmt.Sprintf("%d", m.Year()))
+	}
+	return terms
+}
+
+type builder struct {
+	index Index
+}
+
+func (b *builder) add(doc Document, texts ...string) {
+	id := len(b.index.Docs)
+	b.index.Docs = append(b.index.Docs, doc)
+	for _, text := range texts {
+		for _, token := range Tokenize(text) {
+			ids := b.index.Tokens[token]
+			if len(ids) == 0 || ids[len(ids)-1] != id {
+				b.index.Tokens[token] = append(ids, id)
+			}
+		}
+	}
+}
+
+func (b *builder) addEvent(event *events.Event, typ string) {
+	if event.IsSeparator() {
+		return
+	}
+	texts := []string{event.Name.Orig, event.Location.City, event.Location.Country, stripHtml(string(event.Details))}
+	for _, tag := range event.Tags {
+		texts = append(texts, tag.Name.Orig)
+	}
+	for _, serie := range event.Series {
+		texts = append(texts, serie.Name.Orig)
+	}
+	texts = append(texts, dateTerms(event.Time)...)
+	b.add(Document{event.Name.Orig, event.Slug(), typ, event.Time.Original, place(event.Location)}, texts...)
+}
+
+// Build creates the search index of the upcoming events, the groups and the shops.
+func Build(data events.Data) Index {
+	stop := make([]string, 0, len(stopWords))
+	for word := range stopWords {
+		stop = append(stop, word)
+	}
+	sort.Strings(stop)
+	b := builder{Index{Version, stop, make([]Document, 0), make(map[string][]int)}}
+	for _, event := range data.Events {
+		b.addEvent(event, "event")
+	}
+	for _, group := range data.Groups {
+		b.addEvent(group, "group")
+	}
+	for _, shop := range data.Shops {
+		b.addEvent(shop, "shop")
+	}
+	return b.index
+}
+
+// Search returns the documents matching all tokens of the query; query tokens match as prefixes of indexed tokens.
+// It mirrors the client-side search in static/main.js.
+func (index Index) Search(query string) []Document {
+	var result map[int]struct{}
+	for _, term := range Tokenize(query) {
+		matches := make(map[int]struct{})
+		for token, ids := range index.Tokens {
+			if strings.HasPrefix(token, term) {
+				for _, id := range ids {
+					matches[id] = struct{}{}
+				}
+			}
+		}
+		if result != nil {
+			for id := range result {
+				if _, ok := matches[id]; !ok {
+					delete(result, id)
+				}
+			}
+		} else {
+			result = matches
+		}
+	}
+
+	ids := make([]int, 0, len(result))
+	for id := range result {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	docs := make([]Document, 0, len(ids))
+	for _, id := range ids {
+		docs = append(docs, index.Docs[id])
+	}
+	return docs
+}
+
+// Write writes the index as compact JSON to out/search-<hash>.json and returns the file name relative to out.
+func (index Index) Write(out utils.Path) (string, error) {
+	buf, err := json.Marshal(index)
+	if err != nil {
+		return "", fmt.Errorf("marshal search index: %w", err)
+	}
+	fileName, err := utils.WriteHash(out.Join("search-HASH.json"), buf)
+	if err != nil {
+		return "", fmt.Errorf("write search index: %w", err)
+	}
+	rel, err := filepath.Rel(out.String(), fileName)
+	if err != nil {
+		return "", fmt.Errorf("write search index: %w", err)
+	}
+	return filepath.ToSlash(rel), nil
+}
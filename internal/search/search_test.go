@@ -0,0 +1,126 @@
+package search
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected []string
+	}{
+		{"", []string{}},
+		{"Lauf in der Südstadt", []string{"lauf", "suedstadt"}},
+		{"Trail-Run im Odenwald (21,1 km)", []string{"trail", "run", "odenwald", "21", "km"}},
+		{"Course de l'Île", []string{"course", "de", "ile"}},
+	}
+	for _, tc := range testCases {
+		tokens := Tokenize(tc.input)
+		if len(tokens) != len(tc.expected) {
+			t.Errorf("Tokenize(%q): expected %v, got %v", tc.input, tc.expected, tokens)
+			continue
+		}
+		for i := range tokens {
+			if tokens[i] != tc.expected[i] {
+				t.Errorf("Tokenize(%q): expected %v, got %v", tc.input, tc.expected, tokens)
+				break
+			}
+		}
+	}
+}
+
+func testData(t *testing.T) events.Data {
+	event := func(name, date, location string, tags ...string) *events.Event {
+		timeRange, err := utils.CreateTimeRange(date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := &events.Event{Type: "event", Name: utils.NewName(name), Time: timeRange, Location: events.CreateLocation(location, "")}
+		for _, tag := range tags {
+			e.Tags = append(e.Tags, &events.Tag{Name: utils.NewName(tag)})
+		}
+		return e
+	}
+	return events.Data{
+		Events: []*events.Event{
+			event("Odenwald Trail", "17.05.2025", "Wald-Michelbach", "Traillauf"),
+			event("Brückenlauf", "08.06.2025", "Heidelberg"),
+			event("Mai-Lauf", "01.05.2025", "Mannheim"),
+		},
+		Groups: []*events.Event{
+			{Type: "group", Name: utils.NewName("Lauftreff Odenwald"), Location: events.CreateLocation("Eberbach", "")},
+		},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	index := Build(testData(t))
+	testCases := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{}},
+		{"odenwald", []string{"Odenwald Trail", "Lauftreff Odenwald"}},
+		{"that trail run in the Odenwald in May", []string{}},
+		{"trail odenwald may", []string{"Odenwald Trail"}},
+		{"Trail Odenwald Mai", []string{"Odenwald Trail"}},
+		{"brucken", []string{}},
+		{"brueck", []string{"Brückenlauf"}},
+		{"Brücke", []string{"Brückenlauf"}},
+		{"mai", []string{"Odenwald Trail", "Mai-Lauf"}},
+	}
+	for _, tc := range testCases {
+		docs := index.Search(tc.query)
+		names := make([]string, 0, len(docs))
+		for _, doc := range docs {
+			names = append(names, doc.Name)
+		}
+		if len(names) != len(tc.expected) {
+			t.Errorf("Search(%q): expected %v, got %v", tc.query, tc.expected, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != tc.expected[i] {
+				t.Errorf("Search(%q): expected %v, got %v", tc.query, tc.expected, names)
+				break
+			}
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	out := utils.Path(t.TempDir())
+	index := Build(testData(t))
+
+	name, err := index.Write(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(out.Join(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the name contains the same content hash as produced by CopyHash
+	copied, err := utils.CopyHash(out.Join(name), out.Join("copy", "search-HASH.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(copied) != name {
+		t.Errorf("expected file name %q, got %q", filepath.Base(copied), name)
+	}
+
+	var decoded Index
+	if err := json.Unmarshal(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Version != Version || len(decoded.Docs) != 4 || len(decoded.Tokens["odenwald"]) != 2 {
+		t.Errorf("unexpected index: %+v", decoded)
+	}
+}
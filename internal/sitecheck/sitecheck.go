@@ -0,0 +1,282 @@
+// Package sitecheck verifies the internal links of a generated site offline: every internal reference of the HTML
+// (and CSS) files must resolve to a file of the output tree or to a redirect rule, and every page should be linked
+// from another page.
+package sitecheck
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+	"golang.org/x/net/html"
+)
+
+// Dangling is an internal reference that does not resolve.
+type Dangling struct {
+	Page string // URL path of the referencing file
+	Ref  string // reference as written in the file
+}
+
+type Report struct {
+	Pages    int
+	Refs     int
+	Dangling []Dangling
+	Orphans  []string // URL paths of pages no other page links to
+}
+
+type Checker struct {
+	out       string
+	baseUrl   string
+	ignore    []string // path.Match patterns of pages that may be orphans (e.g. "embed/*")
+	allow     []string // path.Match patterns of paths that may dangle (known gaps)
+	redirects utils.Htaccess
+}
+
+// NewChecker reads the redirect rules of the output directory ('.htaccess' and Netlify '_redirects').
+func NewChecker(out string, baseUrl string, ignore []string, allow []string) (*Checker, error) {
+	redirects := utils.Htaccess{Redirects: make([]utils.Redirect, 0), ErrorDocuments: make(map[int]string)}
+	if f, err := os.Open(filepath.Join(out, ".htaccess")); err == nil {
+		htaccess, err := utils.ParseHtaccess(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parse .htaccess: %w", err)
+		}
+		redirects = htaccess
+	}
+	if buf, err := os.ReadFile(filepath.Join(out, "_redirects")); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(buf))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && !strings.HasPrefix(fields[0], "#") {
+				redirects.Redirects = append(redirects.Redirects, utils.Redirect{From: fields[0], To: fields[1]})
+			}
+		}
+	}
+	return &Checker{out, strings.TrimSuffix(baseUrl, "/"), ignore, allow, redirects}, nil
+}
+
+// urlPath returns the URL path of an output file.
+func (c *Checker) urlPath(fileName string) string {
+	rel, _ := filepath.Rel(c.out, fileName)
+	return "/" + filepath.ToSlash(rel)
+}
+
+// internal returns the path of an internal reference relative to the referencing page; false for external references.
+func (c *Checker) internal(page string, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if c.baseUrl != "" && (ref == c.baseUrl || strings.HasPrefix(ref, c.baseUrl+"/")) {
+		ref = strings.TrimPrefix(ref, c.baseUrl)
+		if ref == "" {
+			ref = "/"
+		}
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" || u.Host != "" || strings.HasPrefix(ref, "//") {
+		return "", false
+	}
+	if u.Path == "" {
+		// fragment or query only: the page itself
+		return "", false
+	}
+	p := u.Path
+	if !strings.HasPrefix(p, "/") {
+		p = path.Join(path.Dir(page), p)
+		if strings.HasSuffix(u.Path, "/") {
+			p += "/"
+		}
+	}
+	return p, true
+}
+
+// resolve returns the URL path of the output file p refers to (following redirects); ok is false for dangling paths.
+func (c *Checker) resolve(p string) (string, bool) {
+	for hops := 0; hops < 5; hops++ {
+		fileName := filepath.Join(c.out, filepath.FromSlash(p))
+		info, err := os.Stat(fileName)
+		if err == nil && info.IsDir() {
+			fileName = filepath.Join(fileName, "index.html")
+			info, err = os.Stat(fileName)
+		}
+		if err == nil && !info.IsDir() {
+			return c.urlPath(fileName), true
+		}
+
+		target, found := c.redirects.Match(p)
+		if !found {
+			return "", false
+		}
+		next, internal := c.internal("/", target)
+		if !internal {
+			return "", true
+		}
+		p = next
+	}
+	return "", false
+}
+
+// refAttributes are the attributes holding URLs.
+var refAttributes = map[string]bool{"href": true, "src": true, "data-index": true}
+
+var skipSchemes = []string{"mailto:", "tel:", "javascript:", "data:"}
+
+// htmlRefs returns the references of an HTML document; isRedirect reports a meta refresh (redirect stub).
+func htmlRefs(buf []byte) (refs []string, isRedirect bool, err error) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(buf))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if errors.Is(tokenizer.Err(), io.EOF) {
+				return refs, isRedirect, nil
+			}
+			return nil, false, tokenizer.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			for _, attr := range token.Attr {
+				if refAttributes[attr.Key] {
+					refs = append(refs, attr.Val)
+				}
+				if token.Data == "meta" && attr.Key == "http-equiv" && strings.EqualFold(attr.Val, "refresh") {
+					isRedirect = true
+				}
+			}
+		}
+	}
+}
+
+var reCssUrl = regexp.MustCompile(`url\(\s*['"]?([^'")]+)['"]?\s*\)`)
+
+func cssRefs(buf []byte) []string {
+	refs := make([]string, 0)
+	for _, match := range reCssUrl.FindAllSubmatch(buf, -1) {
+		refs = append(refs, string(match[1]))
+	}
+	return refs
+}
+
+// sitemapRefs returns the <loc> entries of a sitemap.
+func sitemapRefs(buf []byte) ([]string, error) {
+	var sitemap struct {
+		Urls []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+	}
+	if err := xml.Unmarshal(buf, &sitemap); err != nil {
+		return nil, err
+	}
+	refs := make([]string, 0, len(sitemap.Urls))
+	for _, u := range sitemap.Urls {
+		refs = append(refs, u.Loc)
+	}
+	return refs, nil
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, strings.TrimPrefix(p, "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Check walks the output directory and checks all references.
+func (c *Checker) Check() (Report, error) {
+	report := Report{Dangling: make([]Dangling, 0), Orphans: make([]string, 0)}
+	pages := make([]string, 0)
+	linked := make(map[string]bool)
+	redirectStubs := make(map[string]bool)
+
+	err := filepath.WalkDir(c.out, func(fileName string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		page := c.urlPath(fileName)
+		var refs []string
+		switch {
+		case strings.HasSuffix(fileName, ".html"):
+			buf, err := os.ReadFile(fileName)
+			if err != nil {
+				return err
+			}
+			var isRedirect bool
+			refs, isRedirect, err = htmlRefs(buf)
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", page, err)
+			}
+			report.Pages += 1
+			pages = append(pages, page)
+			redirectStubs[page] = isRedirect
+		case strings.HasSuffix(fileName, ".css"):
+			buf, err := os.ReadFile(fileName)
+			if err != nil {
+				return err
+			}
+			refs = cssRefs(buf)
+		case filepath.Base(fileName) == "sitemap.xml":
+			buf, err := os.ReadFile(fileName)
+			if err != nil {
+				return err
+			}
+			if refs, err = sitemapRefs(buf); err != nil {
+				return fmt.Errorf("parse %s: %w", page, err)
+			}
+		default:
+			return nil
+		}
+
+		seen := make(map[string]bool)
+	refLoop:
+		for _, ref := range refs {
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			for _, scheme := range skipSchemes {
+				if strings.HasPrefix(ref, scheme) {
+					continue refLoop
+				}
+			}
+			p, internal := c.internal(page, ref)
+			if !internal {
+				continue
+			}
+			report.Refs += 1
+			target, ok := c.resolve(p)
+			if !ok {
+				if matchAny(c.allow, p) {
+					continue
+				}
+				report.Dangling = append(report.Dangling, Dangling{page, ref})
+				continue
+			}
+			if target != page && strings.HasSuffix(page, ".html") {
+				linked[target] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	for _, page := range pages {
+		if linked[page] || redirectStubs[page] || page == "/index.html" || page == "/404.html" || matchAny(c.ignore, page) {
+			continue
+		}
+		report.Orphans = append(report.Orphans, page)
+	}
+	sort.Strings(report.Orphans)
+	return report, nil
+}
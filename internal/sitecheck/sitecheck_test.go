@@ -0,0 +1,57 @@
+package sitecheck
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	out := t.TempDir()
+	files := map[string]string{
+		"index.html":      `<html><head><link rel="stylesheet" href="style.css"><link rel="canonical" href="https://example.com/"></head><body><a href="a.html#top">A</a> <a href="/tag/">Tag</a> <a href="/old">Old</a> <a href="/missing.html">Missing</a> <a href="https://elsewhere.com/x">X</a> <a href="mailto:info@example.com">Mail</a></body></html>`,
+		"a.html":          `<html><body><a href="/">Home</a><img src="images/logo.png"><a href="/missing.html">again</a><a href="/missing.html">and again</a></body></html>`,
+		"tag/index.html":  `<html><body><a href="../a.html">A</a><a href="https://example.com/b-gone.html">B</a></body></html>`,
+		"orphan.html":     `<html><body><a href="/">Home</a></body></html>`,
+		"stub.html":       `<html><head><meta http-equiv="refresh" content="0; url=https://example.com/a.html"></head></html>`,
+		"embed/list.html": `<html><body></body></html>`,
+		"style.css":       `body { background: url("images/bg.png"); } .x { background: url(images/known-gap.png) }`,
+		"images/logo.png": ``,
+		"images/bg.png":   ``,
+		".htaccess":       "ErrorDocument 404 /404.html\nRedirect /old /a.html\n",
+	}
+	for name, content := range files {
+		fileName := filepath.Join(out, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(fileName), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fileName, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checker, err := NewChecker(out, "https://example.com/", []string{"embed/*"}, []string{"images/known-*.png"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := checker.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Pages != 6 {
+		t.Errorf("expected 6 pages, got %d", report.Pages)
+	}
+	expected := []Dangling{
+		{"/a.html", "/missing.html"},
+		{"/index.html", "/missing.html"},
+		{"/tag/index.html", "https://example.com/b-gone.html"},
+	}
+	if !reflect.DeepEqual(report.Dangling, expected) {
+		t.Errorf("expected dangling %v, got %v", expected, report.Dangling)
+	}
+	if !reflect.DeepEqual(report.Orphans, []string{"/orphan.html"}) {
+		t.Errorf("expected orphans [/orphan.html], got %v", report.Orphans)
+	}
+}
@@ -0,0 +1,281 @@
+package stats
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+)
+
+// TrailTag marks trail runs; all other events count as road (and other) runs.
+const TrailTag = "traillauf"
+
+// distanceLimits are the upper limits (km) of the distance histogram buckets; the last bucket is open.
+var distanceLimits = []int{10, 20, 30, 50, 75, 100, 150}
+
+const (
+	maxWeekends = 10
+	maxTags     = 15
+)
+
+// Year holds the numbers of a calendar year; cancelled events are counted separately.
+type Year struct {
+	Year         int
+	Events       int
+	Cancelled    int
+	Trail        int
+	Months       [12]int
+	New          int // events held for the first time; -1 for the first year of the data
+	Discontinued int // events of the previous year held neither in this nor in a later year; -1 if unknown yet
+}
+
+// TrailShare returns the share of trail runs in percent.
+func (y Year) TrailShare() int {
+	return percent(y.Trail, y.Events)
+}
+
+// Weekend is a weekend (by its Saturday) with the events on Saturday or Sunday.
+type Weekend struct {
+	Saturday time.Time
+	Events   []*events.Event
+}
+
+func (w Weekend) Label(labels Labels) string {
+	return fmt.Sprintf("%s – %s", labels.Day(w.Saturday, false), labels.Day(w.Saturday.AddDate(0, 0, 1), true))
+}
+
+type TagCount struct {
+	Tag   *events.Tag
+	Count int
+}
+
+type Bucket struct {
+	Label string
+	Count int
+}
+
+// Stats are statistics about all (past and upcoming) events.
+type Stats struct {
+	Years      []Year
+	Months     [12]int
+	Events     int
+	Cancelled  int
+	Trail      int
+	Weekends   []Weekend // busiest weekends, most events first
+	Tags       []TagCount
+	NumTags    int
+	Distances  []Bucket
+	NoLocation int
+}
+
+// TrailShare returns the share of trail runs in percent.
+func (s *Stats) TrailShare() int {
+	return percent(s.Trail, s.Events)
+}
+
+func percent(part, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return (100*part + total/2) / total
+}
+
+func isTrail(event *events.Event) bool {
+	for _, tag := range event.Tags {
+		if tag.Name.Sanitized == TrailTag {
+			return true
+		}
+	}
+	return false
+}
+
+var reNumber = regexp.MustCompile(`^\d+$`)
+
+// recurrenceKey identifies the editions of an event across years: its base name (NAME2) or its name without numbers
+// (years, editions).
+func recurrenceKey(event *events.Event) string {
+	if event.Meta.BaseName.Sanitized != "" {
+		return event.Meta.BaseName.Sanitized
+	}
+	parts := make([]string, 0)
+	for _, part := range strings.Split(event.Name.Sanitized, "-") {
+		if part != "" && !reNumber.MatchString(part) {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+func distanceBucket(km float64) int {
+	for i, limit := range distanceLimits {
+		if km < float64(limit) {
+			return i
+		}
+	}
+	return len(distanceLimits)
+}
+
+func distanceBuckets() []Bucket {
+	buckets := make([]Bucket, 0, len(distanceLimits)+1)
+	lower := 0
+	for _, limit := range distanceLimits {
+		buckets = append(buckets, Bucket{fmt.Sprintf("%d–%d", lower, limit), 0})
+		lower = limit
+	}
+	return append(buckets, Bucket{fmt.Sprintf("≥ %d", lower), 0})
+}
+
+// Compute computes the statistics of the upcoming and past events of data; years after today's year are not over,
+// so events discontinued in them are unknown.
+func Compute(data events.Data, today time.Time) *Stats {
+	allEvents := make([]*events.Event, 0)
+	for _, eventList := range [][]*events.Event{data.Events, data.EventsOld} {
+		for _, event := range eventList {
+			if !event.IsSeparator() && event.Type == "event" && !event.Time.IsZero() {
+				allEvents = append(allEvents, event)
+			}
+		}
+	}
+
+	stats := &Stats{
+		Years:     make([]Year, 0),
+		Weekends:  make([]Weekend, 0),
+		Tags:      make([]TagCount, 0),
+		Distances: distanceBuckets(),
+	}
+	if len(allEvents) == 0 {
+		return stats
+	}
+
+	minYear, maxYear := allEvents[0].Time.Year(), allEvents[0].Time.Year()
+	for _, event := range allEvents {
+		minYear = min(minYear, event.Time.Year())
+		maxYear = max(maxYear, event.Time.Year())
+	}
+	years := make([]Year, maxYear-minYear+1)
+	keys := make([]map[string]bool, maxYear-minYear+1)
+	for i := range years {
+		years[i].Year = minYear + i
+		keys[i] = make(map[string]bool)
+	}
+
+	weekends := make(map[string]*Weekend)
+	tags := make(map[*events.Tag]int)
+	for _, event := range allEvents {
+		from := event.Time.From
+		year := &years[from.Year()-minYear]
+		if key := recurrenceKey(event); key != "" {
+			keys[from.Year()-minYear][key] = true
+		}
+		if event.Cancelled {
+			year.Cancelled += 1
+			stats.Cancelled += 1
+			continue
+		}
+
+		year.Events += 1
+		year.Months[from.Month()-1] += 1
+		stats.Events += 1
+		stats.Months[from.Month()-1] += 1
+		if isTrail(event) {
+			year.Trail += 1
+			stats.Trail += 1
+		}
+		for _, tag := range event.Tags {
+			tags[tag] += 1
+		}
+		if event.Location.HasGeo() {
+			stats.Distances[distanceBucket(event.Location.DistanceKM())].Count += 1
+		} else {
+			stats.NoLocation += 1
+		}
+
+		var saturday time.Time
+		switch from.Weekday() {
+		case time.Saturday:
+			saturday = from
+		case time.Sunday:
+			saturday = from.AddDate(0, 0, -1)
+		default:
+			continue
+		}
+		key := saturday.Format("2006-01-02")
+		weekend, found := weekends[key]
+		if !found {
+			weekend = &Weekend{saturday, make([]*events.Event, 0)}
+			weekends[key] = weekend
+		}
+		weekend.Events = append(weekend.Events, event)
+	}
+
+	for i := range years {
+		years[i].New = -1
+		years[i].Discontinued = -1
+		if i == 0 {
+			continue
+		}
+		years[i].New = 0
+		for key := range keys[i] {
+			if !heldIn(keys[:i], key) {
+				years[i].New += 1
+			}
+		}
+		if years[i].Year < today.Year() {
+			years[i].Discontinued = 0
+			for key := range keys[i-1] {
+				if !heldIn(keys[i:], key) {
+					years[i].Discontinued += 1
+				}
+			}
+		}
+	}
+	stats.Years = years
+
+	for _, weekend := range weekends {
+		stats.Weekends = append(stats.Weekends, *weekend)
+	}
+	sort.Slice(stats.Weekends, func(i, j int) bool {
+		a, b := stats.Weekends[i], stats.Weekends[j]
+		if len(a.Events) != len(b.Events) {
+			return len(a.Events) > len(b.Events)
+		}
+		return a.Saturday.After(b.Saturday)
+	})
+	if len(stats.Weekends) > maxWeekends {
+		stats.Weekends = stats.Weekends[:maxWeekends]
+	}
+	for _, weekend := range stats.Weekends {
+		sort.SliceStable(weekend.Events, func(i, j int) bool {
+			return weekend.Events[i].Time.From.Before(weekend.Events[j].Time.From)
+		})
+	}
+
+	for tag, count := range tags {
+		stats.Tags = append(stats.Tags, TagCount{tag, count})
+	}
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		a, b := stats.Tags[i], stats.Tags[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Tag.Name.Sanitized < b.Tag.Name.Sanitized
+	})
+	stats.NumTags = len(stats.Tags)
+	if len(stats.Tags) > maxTags {
+		stats.Tags = stats.Tags[:maxTags]
+	}
+
+	return stats
+}
+
+func heldIn(years []map[string]bool, key string) bool {
+	for _, keys := range years {
+		if keys[key] {
+			return true
+		}
+	}
+	return false
+}
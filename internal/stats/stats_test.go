@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/events"
+	"github.com/svengiegerich/heidelberg-run/internal/utils"
+)
+
+func testEvent(t *testing.T, name string, date string, coordinates string, tags ...*events.Tag) *events.Event {
+	timeRange, err := utils.CreateTimeRange(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &events.Event{Type: "event", Name: utils.NewName(name), Time: timeRange, Location: events.CreateLocation("", coordinates), Tags: tags}
+}
+
+var testLabels = Labels{
+	Months: [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+	Day: func(t time.Time, withYear bool) string {
+		if withYear {
+			return t.Format("02.01.2006")
+		}
+		return t.Format("02.01.")
+	},
+	Road:  "Straße & Sonstige",
+	Trail: "Trail",
+}
+
+func TestCompute(t *testing.T) {
+	trail := events.CreateTag("Traillauf")
+	volkslauf := events.CreateTag("Volkslauf")
+	cancelled := testEvent(t, "Stadtlauf 2026", "13.06.2026", "49.41,8.69", volkslauf)
+	cancelled.Cancelled = true
+	data := events.Data{
+		EventsOld: []*events.Event{
+			testEvent(t, "Stadtlauf 2024", "08.06.2024", "49.41,8.69", volkslauf), // Saturday
+			testEvent(t, "Odenwald Trail", "09.06.2024", "49.57,8.83", trail),     // Sunday
+			testEvent(t, "Herbstlauf", "16.10.2024", "", volkslauf),
+			testEvent(t, "Stadtlauf 2025", "14.06.2025", "49.41,8.69", volkslauf),
+			testEvent(t, "2. Odenwald Trail", "15.06.2025", "49.57,8.83", trail),
+			{}, // separator
+		},
+		Events: []*events.Event{
+			cancelled,
+			testEvent(t, "Maienlauf", "06.05.2026", "48.78,9.18", volkslauf), // Wednesday, ~90km
+		},
+	}
+
+	s := Compute(data, time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC))
+	if s.Events != 6 || s.Cancelled != 1 || s.Trail != 2 || s.TrailShare() != 33 {
+		t.Errorf("unexpected totals %d/%d/%d", s.Events, s.Cancelled, s.Trail)
+	}
+	if len(s.Years) != 3 {
+		t.Fatalf("expected 3 years, got %v", s.Years)
+	}
+	expected := []Year{
+		{2024, 3, 0, 1, [12]int{5: 2, 9: 1}, -1, -1},
+		{2025, 2, 0, 1, [12]int{5: 2}, 0, 1}, // Herbstlauf discontinued
+		{2026, 1, 1, 0, [12]int{4: 1}, 1, -1},
+	}
+	for i := range expected {
+		if s.Years[i] != expected[i] {
+			t.Errorf("year %d: expected %v, got %v", i, expected[i], s.Years[i])
+		}
+	}
+	if s.Months[5] != 4 || s.Months[4] != 1 || s.Months[9] != 1 {
+		t.Errorf("unexpected months %v", s.Months)
+	}
+
+	if len(s.Weekends) != 2 || s.Weekends[0].Label(testLabels) != "14.06. – 15.06.2025" || len(s.Weekends[0].Events) != 2 || s.Weekends[1].Label(testLabels) != "08.06. – 09.06.2024" {
+		t.Errorf("unexpected weekends %v", s.Weekends)
+	}
+	if len(s.Tags) != 2 || s.Tags[0].Tag != volkslauf || s.Tags[0].Count != 4 || s.Tags[1].Count != 2 {
+		t.Errorf("unexpected tags %v", s.Tags)
+	}
+	if s.NoLocation != 1 || s.Distances[0].Count != 2 || s.Distances[2].Count != 2 || s.Distances[5].Count != 1 {
+		t.Errorf("unexpected distances %v (no location %d)", s.Distances, s.NoLocation)
+	}
+
+	for _, chart := range s.Charts(testLabels) {
+		svg := string(chart.SVG)
+		if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`) || !strings.HasSuffix(svg, "</svg>") {
+			t.Errorf("chart %s: bad svg %s", chart.Name, svg)
+		}
+	}
+	if svg := string(s.YearChart(testLabels).SVG); !strings.Contains(svg, "Straße &amp; Sonstige") || !strings.Contains(svg, "<title>2024 Trail: 1</title>") {
+		t.Errorf("unexpected year chart %s", svg)
+	}
+}
+
+func TestNiceStep(t *testing.T) {
+	for _, tc := range []struct{ max, expected int }{{0, 1}, {3, 1}, {9, 5}, {40, 10}, {70, 20}, {130, 50}} {
+		if step := niceStep(tc.max, 4); step != tc.expected {
+			t.Errorf("niceStep(%d): expected %d, got %d", tc.max, tc.expected, step)
+		}
+	}
+}
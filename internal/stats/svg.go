@@ -0,0 +1,298 @@
+package stats
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	chartWidth  = 640
+	chartHeight = 260
+	chartLeft   = 40 // space for the axis labels
+	chartTop    = 30 // space for the title
+	chartBottom = 30 // space for the bar labels
+	chartFont   = `font-family="sans-serif" font-size="11"`
+
+	colorRoad  = "#485fc7"
+	colorTrail = "#48c78e"
+	colorGone  = "#f14668"
+	colorText  = "#4a4a4a"
+	colorGrid  = "#dbdbdb"
+)
+
+// Labels are the texts of the charts (and the weekend dates) in the language of the page.
+type Labels struct {
+	Months               [12]string // short month names
+	Day                  func(t time.Time, withYear bool) string
+	YearTitle            string
+	MonthTitle           string
+	MonthYearTitle       string
+	TagTitle             string
+	DistanceTitle        string
+	NewDiscontinuedTitle string
+	Road                 string
+	Trail                string
+	Events               string
+	New                  string
+	Discontinued         string
+}
+
+// Chart is an SVG chart; it is embedded in the statistics page and written to File for reuse (e.g. in newsletters).
+type Chart struct {
+	Name  string
+	Title string
+	SVG   template.HTML
+}
+
+func (c Chart) File() string {
+	return fmt.Sprintf("statistik/%s.svg", c.Name)
+}
+
+type chartSeries struct {
+	Name   string
+	Color  string
+	Values []int
+}
+
+func esc(s string) string {
+	return template.HTMLEscapeString(s)
+}
+
+// niceStep returns a step of 1, 2 or 5 times a power of ten, such that about lines steps cover max.
+func niceStep(max int, lines int) int {
+	raw := float64(max) / float64(lines)
+	if raw <= 1 {
+		return 1
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*mag >= raw {
+			return int(m * mag)
+		}
+	}
+	return int(10 * mag)
+}
+
+type svgBuilder struct {
+	strings.Builder
+}
+
+func newSvg(title string, height int) *svgBuilder {
+	b := &svgBuilder{}
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="100%%" role="img" aria-label="%s" %s>`, chartWidth, height, esc(title), chartFont)
+	fmt.Fprintf(b, `<title>%s</title>`, esc(title))
+	fmt.Fprintf(b, `<text x="0" y="14" font-size="13" font-weight="bold" fill="%s">%s</text>`, colorText, esc(title))
+	return b
+}
+
+func (b *svgBuilder) legend(series []chartSeries) {
+	if len(series) < 2 {
+		return
+	}
+	x := chartWidth
+	for i := len(series) - 1; i >= 0; i-- {
+		x -= 14 + 7*len([]rune(series[i].Name)) + 12
+		fmt.Fprintf(b, `<rect x="%d" y="5" width="10" height="10" fill="%s"/>`, x, series[i].Color)
+		fmt.Fprintf(b, `<text x="%d" y="14" fill="%s">%s</text>`, x+14, colorText, esc(series[i].Name))
+	}
+}
+
+func (b *svgBuilder) finish() template.HTML {
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// barChart renders vertical bars with the values of the series stacked or side by side.
+func barChart(title string, labels []string, series []chartSeries, stacked bool) template.HTML {
+	b := newSvg(title, chartHeight)
+	b.legend(series)
+
+	maxValue := 0
+	for i := range labels {
+		sum := 0
+		for _, s := range series {
+			sum += s.Values[i]
+			maxValue = max(maxValue, s.Values[i])
+		}
+		if stacked {
+			maxValue = max(maxValue, sum)
+		}
+	}
+	step := niceStep(maxValue, 4)
+	top := step * int(math.Ceil(float64(max(maxValue, 1))/float64(step)))
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	y := func(value int) float64 {
+		return float64(chartTop) + plotHeight*(1-float64(value)/float64(top))
+	}
+
+	for value := 0; value <= top; value += step {
+		fmt.Fprintf(b, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" stroke="%s"/>`, chartLeft, chartWidth, y(value), y(value), colorGrid)
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" text-anchor="end" fill="%s">%d</text>`, chartLeft-6, y(value)+4, colorText, value)
+	}
+
+	slot := float64(chartWidth-chartLeft) / float64(max(len(labels), 1))
+	groupWidth := slot * 0.7
+	barWidth := groupWidth
+	if !stacked {
+		barWidth = groupWidth / float64(max(len(series), 1))
+	}
+	for i, label := range labels {
+		x := float64(chartLeft) + slot*float64(i) + (slot-groupWidth)/2
+		sum := 0
+		for j, s := range series {
+			value := s.Values[i]
+			base, barX := sum, x
+			if stacked {
+				sum += value
+			} else {
+				base, barX = 0, x+barWidth*float64(j)
+				if value > 0 {
+					fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="%s">%d</text>`, barX+barWidth/2, y(value)-4, colorText, value)
+				}
+			}
+			if value == 0 {
+				continue
+			}
+			fmt.Fprintf(b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s %s: %d</title></rect>`,
+				barX, y(base+value), barWidth, y(base)-y(base+value), s.Color, esc(label), esc(s.Name), value)
+		}
+		if stacked && sum > 0 {
+			fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="%s">%d</text>`, x+groupWidth/2, y(sum)-4, colorText, sum)
+		}
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle" fill="%s">%s</text>`, x+groupWidth/2, chartHeight-chartBottom+16, colorText, esc(label))
+	}
+	return b.finish()
+}
+
+// hbarChart renders horizontal bars, e.g. for long labels.
+func hbarChart(title string, labels []string, values []int, color string) template.HTML {
+	const rowHeight = 20
+	const labelWidth = 160
+	height := chartTop + rowHeight*len(labels) + 10
+	b := newSvg(title, height)
+
+	maxValue := 1
+	for _, value := range values {
+		maxValue = max(maxValue, value)
+	}
+	plotWidth := float64(chartWidth - labelWidth - 40)
+	for i, label := range labels {
+		y := chartTop + rowHeight*i
+		width := plotWidth * float64(values[i]) / float64(maxValue)
+		fmt.Fprintf(b, `<text x="%d" y="%d" text-anchor="end" fill="%s">%s</text>`, labelWidth-6, y+14, colorText, esc(label))
+		fmt.Fprintf(b, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s"><title>%s: %d</title></rect>`,
+			labelWidth, y+3, width, rowHeight-6, color, esc(label), values[i])
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" fill="%s">%d</text>`, float64(labelWidth)+width+4, y+14, colorText, values[i])
+	}
+	return b.finish()
+}
+
+// heatmap renders a table of values with cells shaded by their value.
+func heatmap(title string, rows []string, columns []string, values [][]int, color string) template.HTML {
+	const rowHeight = 24
+	height := chartTop + 20 + rowHeight*len(rows) + 6
+	b := newSvg(title, height)
+
+	maxValue := 1
+	for _, row := range values {
+		for _, value := range row {
+			maxValue = max(maxValue, value)
+		}
+	}
+	cellWidth := float64(chartWidth-chartLeft) / float64(max(len(columns), 1))
+	for j, column := range columns {
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle" fill="%s">%s</text>`, float64(chartLeft)+cellWidth*(float64(j)+0.5), chartTop+12, colorText, esc(column))
+	}
+	for i, row := range rows {
+		y := chartTop + 20 + rowHeight*i
+		fmt.Fprintf(b, `<text x="%d" y="%d" text-anchor="end" fill="%s">%s</text>`, chartLeft-6, y+16, colorText, esc(row))
+		for j, column := range columns {
+			value := values[i][j]
+			opacity := 0.08 + 0.92*float64(value)/float64(maxValue)
+			textColor := colorText
+			if opacity > 0.5 {
+				textColor = "#ffffff"
+			}
+			x := float64(chartLeft) + cellWidth*float64(j)
+			fmt.Fprintf(b, `<rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s" fill-opacity="%.2f"><title>%s %s: %d</title></rect>`,
+				x+1, y+1, cellWidth-2, rowHeight-2, color, opacity, esc(column), esc(row), value)
+			fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle" fill="%s">%d</text>`, x+cellWidth/2, y+16, textColor, value)
+		}
+	}
+	return b.finish()
+}
+
+func (s *Stats) YearChart(labels Labels) Chart {
+	years := make([]string, len(s.Years))
+	road := make([]int, len(s.Years))
+	trail := make([]int, len(s.Years))
+	for i, year := range s.Years {
+		years[i] = fmt.Sprint(year.Year)
+		road[i] = year.Events - year.Trail
+		trail[i] = year.Trail
+	}
+	title := labels.YearTitle
+	return Chart{"jahre", title, barChart(title, years, []chartSeries{{labels.Road, colorRoad, road}, {labels.Trail, colorTrail, trail}}, true)}
+}
+
+func (s *Stats) MonthChart(labels Labels) Chart {
+	title := labels.MonthTitle
+	return Chart{"monate", title, barChart(title, labels.Months[:], []chartSeries{{labels.Events, colorRoad, s.Months[:]}}, true)}
+}
+
+func (s *Stats) MonthYearChart(labels Labels) Chart {
+	rows := make([]string, len(s.Years))
+	values := make([][]int, len(s.Years))
+	for i, year := range s.Years {
+		rows[i] = fmt.Sprint(year.Year)
+		values[i] = year.Months[:]
+	}
+	title := labels.MonthYearTitle
+	return Chart{"monate-jahre", title, heatmap(title, rows, labels.Months[:], values, colorRoad)}
+}
+
+func (s *Stats) TagChart(labels Labels) Chart {
+	tags := make([]string, len(s.Tags))
+	values := make([]int, len(s.Tags))
+	for i, tag := range s.Tags {
+		tags[i] = tag.Tag.Name.Orig
+		values[i] = tag.Count
+	}
+	title := labels.TagTitle
+	return Chart{"kategorien", title, hbarChart(title, tags, values, colorRoad)}
+}
+
+func (s *Stats) DistanceChart(labels Labels) Chart {
+	buckets := make([]string, len(s.Distances))
+	values := make([]int, len(s.Distances))
+	for i, bucket := range s.Distances {
+		buckets[i] = bucket.Label
+		values[i] = bucket.Count
+	}
+	title := labels.DistanceTitle
+	return Chart{"entfernung", title, barChart(title, buckets, []chartSeries{{labels.Events, colorRoad, values}}, true)}
+}
+
+func (s *Stats) NewDiscontinuedChart(labels Labels) Chart {
+	years := make([]string, 0)
+	added := make([]int, 0)
+	discontinued := make([]int, 0)
+	for _, year := range s.Years {
+		if year.New < 0 {
+			continue
+		}
+		years = append(years, fmt.Sprint(year.Year))
+		added = append(added, year.New)
+		discontinued = append(discontinued, max(year.Discontinued, 0))
+	}
+	title := labels.NewDiscontinuedTitle
+	return Chart{"neu-eingestellt", title, barChart(title, years, []chartSeries{{labels.New, colorTrail, added}, {labels.Discontinued, colorGone, discontinued}}, false)}
+}
+
+// Charts returns all charts.
+func (s *Stats) Charts(labels Labels) []Chart {
+	return []Chart{s.YearChart(labels), s.MonthChart(labels), s.MonthYearChart(labels), s.TagChart(labels), s.DistanceChart(labels), s.NewDiscontinuedChart(labels)}
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Asset is a (hashed) asset file of the site.
+type Asset struct {
+	Path      string // relative to the base path
+	Integrity string // Subresource Integrity value
+}
+
+var (
+	assets      = make(map[string]Asset)
+	assetsMutex sync.Mutex
+)
+
+// RegisterAssets makes the assets available to the 'asset' template function.
+func RegisterAssets(named map[string]Asset) {
+	assetsMutex.Lock()
+	defer assetsMutex.Unlock()
+	for name, asset := range named {
+		assets[name] = asset
+	}
+}
+
+// LookupAsset returns the registered asset name (e.g. "main.js").
+func LookupAsset(name string) (Asset, error) {
+	assetsMutex.Lock()
+	defer assetsMutex.Unlock()
+	asset, found := assets[name]
+	if !found {
+		return Asset{}, fmt.Errorf("unknown asset '%s'", name)
+	}
+	return asset, nil
+}
@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
 )
 
 var dateRe1 = regexp.MustCompile(`^\s*(\d+)\.(\d+)\.(\d\d\d\d)\s*$`)
@@ -105,41 +107,24 @@ func CreateTimeRange(original string) (TimeRange, error) {
 	return TimeRange{original, formatted, from, to}, nil
 }
 
-var germanWeekdays = map[time.Weekday]string{
-	time.Monday:    "Montag",
-	time.Tuesday:   "Dienstag",
-	time.Wednesday: "Mittwoch",
-	time.Thursday:  "Donnerstag",
-	time.Friday:    "Freitag",
-	time.Saturday:  "Samstag",
-	time.Sunday:    "Sonntag",
-}
-
 func WeekdayStr(d time.Weekday) string {
-	if name, ok := germanWeekdays[d]; ok {
-		return name
-	}
-	return "Sonntag"
+	return i18n.DE.Weekday(d)
 }
 
-var germanMonths = map[time.Month]string{
-	time.January:   "Januar",
-	time.February:  "Februar",
-	time.March:     "März",
-	time.April:     "April",
-	time.May:       "Mai",
-	time.June:      "Juni",
-	time.July:      "Juli",
-	time.August:    "August",
-	time.September: "September",
-	time.October:   "Oktober",
-	time.November:  "November",
-	time.December:  "Dezember",
+func MonthStr(m time.Month) string {
+	return i18n.DE.Month(m)
 }
 
-func MonthStr(m time.Month) string {
-	if name, ok := germanMonths[m]; ok {
-		return name
+// Localized returns the time range with all dates formatted according to locale.
+func (tr TimeRange) Localized(locale i18n.Locale) string {
+	if locale.IsDefault() || tr.IsZero() {
+		return tr.Formatted
 	}
-	return "Dezember"
+	return dateRe.ReplaceAllStringFunc(tr.Original, func(s string) string {
+		date, err := ParseDate(s)
+		if err != nil {
+			return s
+		}
+		return locale.FormatDate(date)
+	})
 }
@@ -1,10 +1,11 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
-	"io"
 	"os"
-	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/flopp/go-filehash"
@@ -28,31 +29,28 @@ func Copy(sourceFileName, targetFileName string) error {
 		return fmt.Errorf("copy %s to %s: %w", sourceFileName, targetFileName, err)
 	}
 
-	source, err := os.Open(sourceFileName)
+	buf, err := os.ReadFile(sourceFileName)
 	if err != nil {
 		return wrapErr(err)
 	}
-	defer source.Close()
 
-	if err := MakeDir(filepath.Dir(targetFileName)); err != nil {
-		return wrapErr(err)
-	}
-
-	destination, err := os.Create(targetFileName)
-	if err != nil {
-		return wrapErr(err)
-	}
-	defer destination.Close()
-
-	_, err = io.Copy(destination, source)
-	if err != nil {
+	if _, err := WriteFileIfChanged(targetFileName, buf); err != nil {
 		return wrapErr(err)
 	}
 	return nil
 }
 
+// CopyHash copies src to dst, replacing the last occurence of "HASH" in dst by the hash of the file's content.
+// The function returns the modified target filename.
 func CopyHash(src, dst string) (string, error) {
-	return filehash.Copy(src, dst, "HASH")
+	if pos := strings.LastIndex(dst, "HASH"); pos != -1 {
+		hash, err := filehash.Compute(src)
+		if err != nil {
+			return "", fmt.Errorf("copy %s to %s: %w", src, dst, err)
+		}
+		dst = dst[:pos] + hash + dst[pos+len("HASH"):]
+	}
+	return dst, Copy(src, dst)
 }
 
 func MustCopyHash(src, dst string) string {
@@ -62,3 +60,31 @@ func MustCopyHash(src, dst string) string {
 	}
 	return res
 }
+
+// WriteHash writes buf to targetFileName, replacing the last occurence of "HASH" by the content hash (same format as CopyHash).
+// The function returns the modified target filename.
+func WriteHash(targetFileName string, buf []byte) (string, error) {
+	if pos := strings.LastIndex(targetFileName, "HASH"); pos != -1 {
+		targetFileName = fmt.Sprintf("%s%.8x%s", targetFileName[:pos], sha256.Sum256(buf), targetFileName[pos+len("HASH"):])
+	}
+	if _, err := WriteFileIfChanged(targetFileName, buf); err != nil {
+		return "", fmt.Errorf("write %s: %w", targetFileName, err)
+	}
+	return targetFileName, nil
+}
+
+func WriteJSON(fileName string, data any) error {
+	wrapErr := func(err error) error {
+		return fmt.Errorf("write json to %s: %w", fileName, err)
+	}
+
+	buf, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return wrapErr(err)
+	}
+
+	if _, err := WriteFileIfChanged(fileName, buf); err != nil {
+		return wrapErr(err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 
 	"github.com/flopp/go-compass"
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
 )
 
 func deg2rad(d float64) float64 {
@@ -45,21 +46,30 @@ func DistanceBearing(lat1deg, lon1deg, lat2deg, lon2deg float64) (float64, float
 	return distance, bearing
 }
 
-var directionMap = map[compass.Direction]string{
-	compass.N:  "nördl.",
-	compass.NE: "nordöstl.",
-	compass.E:  "östl.",
-	compass.SE: "südostl.",
-	compass.S:  "südl.",
-	compass.SW: "südwestl.",
-	compass.W:  "westl.",
-	compass.NW: "nordwestl.",
+var directionCodes = map[compass.Direction]string{
+	compass.N:  "N",
+	compass.NE: "NE",
+	compass.E:  "E",
+	compass.SE: "SE",
+	compass.S:  "S",
+	compass.SW: "SW",
+	compass.W:  "W",
+	compass.NW: "NW",
 }
 
-func ApproxDirection(deg float64) string {
+// DirectionCode returns the approximate compass direction (N, NE, E, ...) of the bearing deg.
+func DirectionCode(deg float64) string {
 	direction := compass.GetDirection(deg, compass.Resolution8)
-	if result, ok := directionMap[direction]; ok {
-		return result
+	if code, ok := directionCodes[direction]; ok {
+		return code
 	}
 	return "???"
 }
+
+func ApproxDirection(deg float64) string {
+	code := DirectionCode(deg)
+	if code == "???" {
+		return code
+	}
+	return i18n.DE.Direction(code)
+}
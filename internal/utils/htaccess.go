@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Redirect is a 'Redirect' rule of an .htaccess file: From is a path prefix, matching whole path segments.
+type Redirect struct {
+	From string
+	To   string
+}
+
+// Htaccess holds the rules of an .htaccess file that matter for a static site.
+type Htaccess struct {
+	Redirects      []Redirect
+	ErrorDocuments map[int]string
+}
+
+// ParseHtaccess reads the 'Redirect' and 'ErrorDocument' directives; all other directives are ignored.
+func ParseHtaccess(r io.Reader) (Htaccess, error) {
+	htaccess := Htaccess{make([]Redirect, 0), make(map[int]string)}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line += 1
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "Redirect":
+			// optional status argument: Redirect [status] from to
+			if len(fields) == 4 {
+				fields = append(fields[:1], fields[2:]...)
+			}
+			if len(fields) != 3 {
+				return Htaccess{}, fmt.Errorf("line %d: bad Redirect directive", line)
+			}
+			htaccess.Redirects = append(htaccess.Redirects, Redirect{fields[1], fields[2]})
+		case "ErrorDocument":
+			if len(fields) != 3 {
+				return Htaccess{}, fmt.Errorf("line %d: bad ErrorDocument directive", line)
+			}
+			code, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return Htaccess{}, fmt.Errorf("line %d: bad status code '%s'", line, fields[1])
+			}
+			htaccess.ErrorDocuments[code] = fields[2]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Htaccess{}, err
+	}
+	return htaccess, nil
+}
+
+// Match returns the redirect target of path (like Apache, the rest of the path is appended to the target).
+func (h Htaccess) Match(path string) (string, bool) {
+	for _, redirect := range h.Redirects {
+		if path == redirect.From {
+			return redirect.To, true
+		}
+		prefix := strings.TrimSuffix(redirect.From, "/") + "/"
+		if strings.HasPrefix(path, prefix) {
+			return strings.TrimSuffix(redirect.To, "/") + "/" + strings.TrimPrefix(path, prefix), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHtaccess(t *testing.T) {
+	htaccess, err := ParseHtaccess(strings.NewReader(`# comment
+ErrorDocument 404 /404.html
+Redirect /parkrun /bahnstadtpromenade-parkrun.html
+Redirect 301 /event/old/ https://example.com/new
+RewriteEngine On
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc := htaccess.ErrorDocuments[404]; doc != "/404.html" {
+		t.Errorf("expected error document '/404.html', got '%s'", doc)
+	}
+
+	for _, c := range []struct {
+		path   string
+		target string
+		ok     bool
+	}{
+		{"/parkrun", "/bahnstadtpromenade-parkrun.html", true},
+		{"/parkrun/", "/bahnstadtpromenade-parkrun.html/", true},
+		{"/parkruns", "", false},
+		{"/event/old/x.html", "https://example.com/new/x.html", true},
+		{"/event/old", "", false},
+		{"/", "", false},
+	} {
+		target, ok := htaccess.Match(c.path)
+		if target != c.target || ok != c.ok {
+			t.Errorf("Match(%s): expected '%s' %v, got '%s' %v", c.path, c.target, c.ok, target, ok)
+		}
+	}
+
+	if _, err := ParseHtaccess(strings.NewReader("Redirect /a\n")); err == nil {
+		t.Errorf("expected error for bad Redirect directive")
+	}
+}
@@ -3,14 +3,21 @@ package utils
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+const userAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/104.0.0.0 Safari/537.36"
+
+// LinkChecker checks external links; it is safe for concurrent use.
 type LinkChecker struct {
-	client       *http.Client
-	linksChecked int
-	issuesFound  int
+	client        *http.Client
+	retries       int           // retries of rate limited requests (429, 503 with 'Retry-After')
+	maxRetryAfter time.Duration // longer 'Retry-After' delays count as failure
+	linksChecked  atomic.Int64
+	issuesFound   atomic.Int64
 }
 
 func NewLinkChecker() *LinkChecker {
@@ -18,45 +25,95 @@ func NewLinkChecker() *LinkChecker {
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		linksChecked: 0,
-		issuesFound:  0,
+		retries:       2,
+		maxRetryAfter: 60 * time.Second,
 	}
 }
 
-// Check validates that the given URL is reachable (by sending a GET request).
-// It returns nil if the link is valid, otherwise an appropriate error.
-func (lc *LinkChecker) Check(url string) error {
-	lc.linksChecked++
-
-	// check that the url starts with http or https
-	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
-		lc.issuesFound++
-		return fmt.Errorf("invalid URL (no http:// or https://)")
+// parseRetryAfter parses the value of a 'Retry-After' header (seconds or HTTP date).
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
 	}
+	return 0, false
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// request sends a single request and returns the status code and the 'Retry-After' header.
+func (lc *LinkChecker) request(method string, url string) (int, string, error) {
+	req, err := http.NewRequest(method, url, nil)
 	if err != nil {
-		lc.issuesFound++
-		return err
+		return 0, "", err
 	}
-	req.Header.Add("user-agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/104.0.0.0 Safari/537.36")
+	req.Header.Add("user-agent", userAgent)
 
 	resp, err := lc.client.Do(req)
 	if err != nil {
-		lc.issuesFound++
-		return err
+		return 0, "", err
 	}
 	defer resp.Body.Close()
+	return resp.StatusCode, resp.Header.Get("Retry-After"), nil
+}
+
+// fetch checks url with a HEAD request, falling back to GET for servers not supporting HEAD (405, 501); any other
+// response (e.g. a rate limited 429) is returned as is.
+func (lc *LinkChecker) fetch(url string) (int, string, error) {
+	status, retryAfter, err := lc.request(http.MethodHead, url)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		return lc.request(http.MethodGet, url)
+	}
+	return status, retryAfter, err
+}
+
+// Check validates that the given URL is reachable (by sending a HEAD or GET request); rate limited requests are
+// retried after the delay given by the server.
+// It returns nil if the link is valid, otherwise an appropriate error.
+func (lc *LinkChecker) Check(url string) error {
+	lc.linksChecked.Add(1)
+	err := lc.check(url)
+	if err != nil {
+		lc.issuesFound.Add(1)
+	}
+	return err
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
-		lc.issuesFound++
-		return fmt.Errorf("invalid URL (status code %d)", resp.StatusCode)
+func (lc *LinkChecker) check(url string) error {
+	// check that the url starts with http or https
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return fmt.Errorf("invalid URL (no http:// or https://)")
 	}
 
-	return nil
+	for attempt := 0; ; attempt += 1 {
+		status, retryAfter, err := lc.fetch(url)
+		if err != nil {
+			return err
+		}
+		if status >= 200 && status < 400 {
+			return nil
+		}
+		if (status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable) && attempt < lc.retries {
+			if delay, ok := parseRetryAfter(retryAfter, time.Now()); ok && delay <= lc.maxRetryAfter {
+				time.Sleep(delay)
+				continue
+			}
+		}
+		return fmt.Errorf("invalid URL (status code %d)", status)
+	}
 }
 
 // Stats returns the number of links checked and the number of issues found.
 func (lc *LinkChecker) Stats() (int, int) {
-	return lc.linksChecked, lc.issuesFound
+	return int(lc.linksChecked.Load()), int(lc.issuesFound.Load())
 }
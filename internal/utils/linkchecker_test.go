@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLinkChecker(t *testing.T) {
+	var limitedMutex sync.Mutex
+	var limited []time.Time // requests of '/limited'
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/limited":
+			// rate limited (HEAD and GET) until one second after the first request
+			limitedMutex.Lock()
+			defer limitedMutex.Unlock()
+			limited = append(limited, time.Now())
+			if time.Since(limited[0]) < time.Second {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	lc := NewLinkChecker()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := lc.Check(server.URL + "/ok"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := lc.Check(server.URL + "/nohead"); err != nil {
+		t.Errorf("expected GET fallback, got %v", err)
+	}
+	if err := lc.Check(server.URL + "/limited"); err != nil {
+		t.Errorf("expected retry after 'Retry-After', got %v", err)
+	}
+	if len(limited) != 2 || limited[1].Sub(limited[0]) < time.Second {
+		t.Errorf("expected a single retry after the 'Retry-After' delay, got requests at %v", limited)
+	}
+	if err := lc.Check(server.URL + "/missing"); err == nil {
+		t.Errorf("expected error for missing page")
+	}
+	if err := lc.Check("ftp://example.com"); err == nil {
+		t.Errorf("expected error for bad scheme")
+	}
+	if checked, issues := lc.Stats(); checked != 14 || issues != 2 {
+		t.Errorf("expected 14 checked and 2 issues, got %d and %d", checked, issues)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, time.May, 17, 12, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		value string
+		delay time.Duration
+		ok    bool
+	}{
+		{"120", 120 * time.Second, true},
+		{"Sun, 17 May 2026 12:00:30 GMT", 30 * time.Second, true},
+		{"Sun, 17 May 2026 11:00:00 GMT", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	} {
+		delay, ok := parseRetryAfter(c.value, now)
+		if delay != c.delay || ok != c.ok {
+			t.Errorf("parseRetryAfter(%q): expected %v %v, got %v %v", c.value, c.delay, c.ok, delay, ok)
+		}
+	}
+}
+
+func TestLinkHistory(t *testing.T) {
+	history := &LinkHistory{make(map[string]LinkState)}
+	fail := fmt.Errorf("status code 500")
+	for run := 0; run < 3; run++ {
+		now := time.Date(2026, time.May, 17+run, 0, 0, 0, 0, time.UTC)
+		history.Record("https://flaky.example.com", fail, now)
+		if run < 2 {
+			history.Record("https://gone.example.com", nil, now)
+		}
+		history.Prune(now)
+	}
+	if state := history.Links["https://flaky.example.com"]; state.Failures != 3 || state.LastError != fail.Error() {
+		t.Errorf("unexpected state %v", state)
+	}
+	if _, found := history.Links["https://gone.example.com"]; found {
+		t.Errorf("expected unchecked link to be pruned")
+	}
+	state := history.Record("https://flaky.example.com", nil, time.Date(2026, time.May, 20, 0, 0, 0, 0, time.UTC))
+	if state.Failures != 0 || state.LastError != "" {
+		t.Errorf("expected reset after success, got %v", state)
+	}
+}
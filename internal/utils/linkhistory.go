@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// LinkState is the check history of a single link.
+type LinkState struct {
+	Failures    int       `json:"failures"` // number of consecutive failed runs
+	LastError   string    `json:"last_error,omitempty"`
+	LastChecked time.Time `json:"last_checked"`
+	LastOk      time.Time `json:"last_ok"`
+}
+
+// LinkHistory keeps the link states across runs, so that flaky sites are only flagged after failing repeatedly.
+type LinkHistory struct {
+	Links map[string]LinkState `json:"links"`
+}
+
+// LoadLinkHistory reads the history file; a missing file yields an empty history.
+func LoadLinkHistory(path string) (*LinkHistory, error) {
+	history := &LinkHistory{make(map[string]LinkState)}
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load link history file '%s': %w", path, err)
+	}
+	if err := json.Unmarshal(buf, history); err != nil {
+		return nil, fmt.Errorf("unmarshall link history data: %w", err)
+	}
+	if history.Links == nil {
+		history.Links = make(map[string]LinkState)
+	}
+	return history, nil
+}
+
+func (h *LinkHistory) Save(path string) error {
+	return WriteJSON(path, h)
+}
+
+// Record adds the result of checking url in the run at time now.
+func (h *LinkHistory) Record(url string, err error, now time.Time) LinkState {
+	state := h.Links[url]
+	state.LastChecked = now
+	if err != nil {
+		state.Failures += 1
+		state.LastError = err.Error()
+	} else {
+		state.Failures = 0
+		state.LastError = ""
+		state.LastOk = now
+	}
+	h.Links[url] = state
+	return state
+}
+
+// Prune removes the links that were not checked in the run at time now (i.e. links that are gone).
+func (h *LinkHistory) Prune(now time.Time) {
+	for url, state := range h.Links {
+		if !state.LastChecked.Equal(now) {
+			delete(h.Links, url)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+var reTimestamp = regexp.MustCompile(`<span class="timestamp">[^<]*</span>`)
+var reDtStamp = regexp.MustCompile(`DTSTAMP(:|%3A)[0-9]{8}T[0-9]{6}Z`) // in .ics files and (url-encoded) in calendar data URLs
+var reGenerated = regexp.MustCompile(`"generated": "[^"]*"`)           // envelope of the JSON API files
+var reScript = regexp.MustCompile(`<script [^>]*>`)
+var reStyle = regexp.MustCompile(`<link [^>]*rel="?stylesheet"?[^>]*>`)
+
+// pageHashes maps the file names of the rendered pages to their content hashes (see PageHash).
+var (
+	pageHashes      = make(map[string]string)
+	pageHashesMutex sync.Mutex
+)
+
+// stripVolatile removes the parts of a generated file that change on every run (build timestamp, calendar DTSTAMPs,
+// generation time of the JSON API files).
+func stripVolatile(buf []byte) []byte {
+	buf = reTimestamp.ReplaceAll(buf, nil)
+	buf = reGenerated.ReplaceAll(buf, nil)
+	return reDtStamp.ReplaceAll(buf, nil)
+}
+
+// PageHash computes the hash of a rendered page, ignoring its volatile parts and the (hashed) script and style references.
+func PageHash(buf []byte) string {
+	buf = stripVolatile(buf)
+	buf = reScript.ReplaceAll(buf, nil)
+	buf = reStyle.ReplaceAll(buf, nil)
+	return fmt.Sprintf("%.8x", sha256.Sum256(buf))
+}
+
+// LookupPageHash returns the hash of a page rendered during this run.
+func LookupPageHash(fileName string) (string, bool) {
+	pageHashesMutex.Lock()
+	defer pageHashesMutex.Unlock()
+	hash, ok := pageHashes[filepath.Clean(fileName)]
+	return hash, ok
+}
+
+// WriteFileIfChanged writes buf to fileName unless the file already has the same content (ignoring volatile parts),
+// so that the modification times of unchanged files stay put.
+func WriteFileIfChanged(fileName string, buf []byte) (bool, error) {
+	if old, err := os.ReadFile(fileName); err == nil {
+		if bytes.Equal(stripVolatile(old), stripVolatile(buf)) {
+			return false, nil
+		}
+	}
+
+	if err := MakeDir(filepath.Dir(fileName)); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(fileName, buf, 0o644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writePage writes a rendered page (if changed) and records its hash for the sitemap.
+func writePage(fileName string, buf []byte) error {
+	hash := PageHash(buf)
+	pageHashesMutex.Lock()
+	pageHashes[filepath.Clean(fileName)] = hash
+	pageHashesMutex.Unlock()
+	if _, err := WriteFileIfChanged(fileName, buf); err != nil {
+		return fmt.Errorf("write output file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteFileIfChanged(t *testing.T) {
+	fileName := Path(t.TempDir()).Join("sub", "page.html")
+	page := func(content, timestamp string) []byte {
+		return []byte(`<p>` + content + `</p><span class="timestamp">` + timestamp + `</span>`)
+	}
+
+	for _, tc := range []struct {
+		buf      []byte
+		expected bool
+	}{
+		{page("a", "2025-01-01 10:00:00"), true},
+		{page("a", "2025-01-01 10:00:00"), false},
+		{page("a", "2025-01-02 11:00:00"), false},
+		{page("b", "2025-01-02 11:00:00"), true},
+	} {
+		changed, err := WriteFileIfChanged(fileName, tc.buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != tc.expected {
+			t.Errorf("%s: expected changed=%v, got %v", tc.buf, tc.expected, changed)
+		}
+	}
+
+	buf, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != string(page("b", "2025-01-02 11:00:00")) {
+		t.Errorf("unexpected file content: %s", buf)
+	}
+}
+
+func TestWriteFileIfChangedJSON(t *testing.T) {
+	fileName := Path(t.TempDir()).Join("events.json")
+	file := func(events, generated string) []byte {
+		return []byte(`{
+  "version": 1,
+  "generated": "` + generated + `",
+  "events": [` + events + `]
+}`)
+	}
+
+	for _, tc := range []struct {
+		buf      []byte
+		expected bool
+	}{
+		{file(`"a"`, "2025-01-01T10:00:00+01:00"), true},
+		{file(`"a"`, "2025-01-02T11:00:00+01:00"), false},
+		{file(`"a", "b"`, "2025-01-03T12:00:00+01:00"), true},
+	} {
+		changed, err := WriteFileIfChanged(fileName, tc.buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != tc.expected {
+			t.Errorf("%s: expected changed=%v, got %v", tc.buf, tc.expected, changed)
+		}
+	}
+}
+
+func TestPageHash(t *testing.T) {
+	page := func(style, canonical string) []byte {
+		return []byte(`<link rel="stylesheet" href="/style-` + style + `.css"/><link rel="canonical" href="` + canonical + `"/><p>a</p>`)
+	}
+	if PageHash(page("1", "https://example.com/a.html")) != PageHash(page("2", "https://example.com/a.html")) {
+		t.Errorf("expected the stylesheet reference to be ignored")
+	}
+	if PageHash(page("1", "https://example.com/a.html")) == PageHash(page("1", "https://example.com/b.html")) {
+		t.Errorf("expected a changed canonical link to change the hash")
+	}
+}
+
+func TestSitemapLastmod(t *testing.T) {
+	out := Path(t.TempDir())
+	hashFile := out.Join("hashes")
+	sitemapFile := out.Join("sitemap.xml")
+	day := func(d int) time.Time {
+		return time.Date(2025, time.March, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	// page not rendered in this run & hash file of an older version, keyed by output file name
+	if err := os.WriteFile(out.Join("old.html"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(hashFile, []byte(out.Join("old.html")+"\t"+PageHash([]byte("old"))+"\t2024-12-24\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sitemap := CreateSitemap("https://example.com")
+	sitemap.Add("", "index.html", "Index", "")
+	sitemap.Add("old.html", "old.html", "Old", "")
+
+	render := func(content string, now time.Time) string {
+		if err := writePage(out.Join("index.html"), []byte(`<p>`+content+`</p><script src="main-`+now.Format("02")+`.js">`)); err != nil {
+			t.Fatal(err)
+		}
+		if err := sitemap.Gen(sitemapFile, hashFile, out, now); err != nil {
+			t.Fatal(err)
+		}
+		buf, err := os.ReadFile(sitemapFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf)
+	}
+
+	if s := render("a", day(1)); !strings.Contains(s, "<loc>https://example.com</loc><lastmod>2025-03-01</lastmod>") || !strings.Contains(s, "<loc>https://example.com/old.html</loc><lastmod>2024-12-24</lastmod>") {
+		t.Errorf("run 1: unexpected sitemap %s", s)
+	}
+	if s := render("a", day(2)); !strings.Contains(s, "<lastmod>2025-03-01</lastmod>") {
+		t.Errorf("run 2: unexpected sitemap %s", s)
+	}
+	if s := render("b", day(3)); !strings.Contains(s, "<loc>https://example.com</loc><lastmod>2025-03-03</lastmod>") {
+		t.Errorf("run 3: unexpected sitemap %s", s)
+	}
+
+	hashes := readHashFile(hashFile)
+	if _, ok := hashes["/"]; !ok {
+		t.Errorf("hash file not keyed by slug: %v", hashes)
+	}
+	if old, ok := hashes["/old.html"]; !ok || old.date != "2024-12-24" {
+		t.Errorf("history of old.html lost: %v", hashes)
+	}
+}
@@ -2,12 +2,15 @@ package utils
 
 import (
 	"bufio"
-	"crypto/sha256"
+	"bytes"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"path/filepath"
 	"regexp"
+	"sort"
+	"sync"
+	"time"
 )
 
 type FileHashDate struct {
@@ -23,26 +26,32 @@ type SitemapEntry struct {
 	Category string
 }
 
+// Sitemap collects the pages of the site; Add and AddCategory are safe for concurrent use.
 type Sitemap struct {
 	BaseUrl    Url
 	Categories []string
 	Entries    []*SitemapEntry
+	mutex      sync.Mutex
 }
 
 func CreateSitemap(baseUrl Url) *Sitemap {
-	return &Sitemap{baseUrl, make([]string, 0), make([]*SitemapEntry, 0)}
+	return &Sitemap{BaseUrl: baseUrl, Categories: make([]string, 0), Entries: make([]*SitemapEntry, 0)}
 }
 
 func (sitemap *Sitemap) AddCategory(name string) {
+	sitemap.mutex.Lock()
+	defer sitemap.mutex.Unlock()
 	sitemap.Categories = append(sitemap.Categories, name)
 }
 
 func (sitemap *Sitemap) Add(slug string, slugfile string, name string, category string) {
+	sitemap.mutex.Lock()
+	defer sitemap.mutex.Unlock()
 	sitemap.Entries = append(sitemap.Entries, &SitemapEntry{slug, slugfile, name, category})
 }
 
-func genSitemapEntry(f *os.File, url string, timeStamp string) {
-	f.WriteString(fmt.Sprintf("<url><loc>%s</loc><lastmod>%s</lastmod></url>\n", url, timeStamp))
+func genSitemapEntry(f io.Writer, url string, timeStamp string) {
+	fmt.Fprintf(f, "<url><loc>%s</loc><lastmod>%s</lastmod></url>\n", url, timeStamp)
 }
 
 func AddSitemapEntry(entries []string, slug string) []string {
@@ -84,101 +93,73 @@ func writeHashFile(fileName string, m map[string]*FileHashDate) {
 	}
 	defer f.Close()
 
-	for _, data := range m {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data := m[name]
 		f.WriteString(fmt.Sprintf("%s\t%s\t%s\n", data.name, data.hash, data.date))
 	}
 }
 
-var reTimestamp = regexp.MustCompile(`<span class="timestamp">[^<]*</span>`)
-var reScript = regexp.MustCompile(`<script [^>]*>`)
-var reStyle = regexp.MustCompile(`<link [^>]*>`)
-
-func replaceRegexp(s []byte, r regexp.Regexp) []byte {
-	for {
-		match := r.FindIndex(s)
-		if match != nil {
-			matchStart := match[0]
-			matchEnd := match[1]
-			replaced := make([]byte, 0, len(s))
-			replaced = append(replaced, s[:matchStart]...)
-			replaced = append(replaced, s[matchEnd:]...)
-			s = replaced
-		} else {
-			break
-		}
+// pageHash returns the hash of the page as recorded while rendering it (or computes it from the written file).
+func pageHash(fileName string) (string, error) {
+	if hash, ok := LookupPageHash(fileName); ok {
+		return hash, nil
 	}
-	return s
-}
-
-func determineHash(fileName string) (string, error) {
 	buf, err := os.ReadFile(fileName)
 	if err != nil {
 		return "", err
 	}
-
-	buf = replaceRegexp(buf, *reTimestamp)
-	buf = replaceRegexp(buf, *reScript)
-	buf = replaceRegexp(buf, *reStyle)
-
-	h := sha256.New()
-	h.Write(buf)
-
-	return fmt.Sprintf("%.8x", h.Sum(nil)), nil
-}
-
-func getMtimeYMD(filePath string) string {
-	if t, err := GetMtime(filePath); err != nil {
-		return ""
-	} else {
-		return t.Format("2006-01-02")
-	}
+	return PageHash(buf), nil
 }
 
-func (sitemap Sitemap) Gen(fileName string, hashFileName string, outDir Path) error {
-	if err := os.MkdirAll(filepath.Dir(fileName), 0770); err != nil {
-		return err
-	}
-
-	f, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
+// Gen writes the sitemap; the lastmod date of an entry is the date its page content last changed (as recorded in the hash file).
+func (sitemap *Sitemap) Gen(fileName string, hashFileName string, outDir Path, now time.Time) error {
+	var f bytes.Buffer
 	m := readHashFile(hashFileName)
 	mNew := make(map[string]*FileHashDate)
+	today := now.Format("2006-01-02")
 
 	f.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
 	f.WriteString("<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n")
 
 	for _, entry := range sitemap.Entries {
+		// key by slug, so that the history survives a change of the output directory
+		key := "/" + entry.Slug
 		fileName := outDir.Join(entry.SlugFile)
-		timeStamp := getMtimeYMD(fileName)
-		if timeStamp == "" {
-			log.Printf("cannot get mtime '%s'", fileName)
-		}
-		currentHash, err := determineHash(fileName)
+		currentHash, err := pageHash(fileName)
 		if err != nil {
 			log.Printf("cannot create hash for '%s': %v", fileName, err)
 		}
 
-		oldHash, ok := m[fileName]
+		timeStamp := today
+		oldHash, ok := m[key]
+		if !ok {
+			// hash files of older versions are keyed by the output file name
+			oldHash, ok = m[fileName]
+		}
 		if ok {
 			if currentHash == oldHash.hash {
 				timeStamp = oldHash.date
 			}
 		} else {
-			log.Printf("initial hash for: %s", fileName)
+			log.Printf("initial hash for: %s", key)
 		}
-		mNew[fileName] = &FileHashDate{fileName, currentHash, timeStamp}
+		mNew[key] = &FileHashDate{key, currentHash, timeStamp}
 
-		genSitemapEntry(f, sitemap.BaseUrl.Join(entry.Slug), timeStamp)
+		genSitemapEntry(&f, sitemap.BaseUrl.Join(entry.Slug), timeStamp)
 	}
 
 	f.WriteString("</urlset>")
 
 	writeHashFile(hashFileName, mNew)
 
+	if _, err := WriteFileIfChanged(fileName, f.Bytes()); err != nil {
+		return fmt.Errorf("write sitemap: %w", err)
+	}
 	return nil
 }
 
@@ -187,7 +168,7 @@ type SitemapCategory struct {
 	Links []*Link
 }
 
-func (sitemap Sitemap) GenHTML() []SitemapCategory {
+func (sitemap *Sitemap) GenHTML() []SitemapCategory {
 	byCategory := make(map[string][]*SitemapEntry)
 	for _, c := range sitemap.Categories {
 		byCategory[c] = make([]*SitemapEntry, 0)
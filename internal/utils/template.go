@@ -4,18 +4,55 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
-	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/svengiegerich/heidelberg-run/internal/i18n"
 	"github.com/tdewolff/minify/v2"
 	"github.com/tdewolff/minify/v2/html"
 )
 
-var templates = make(map[string]*template.Template)
+var (
+	templates      = make(map[string]*template.Template)
+	renderOnly     map[string]bool // nil: render all templates (see RenderOnly)
+	templatesMutex sync.Mutex
+)
+
+// RenderOnly restricts the following Execute* calls to the named templates; the pages of all other templates are
+// skipped (and stay as they are on disk). Without names, all templates are rendered again.
+func RenderOnly(names ...string) {
+	templatesMutex.Lock()
+	defer templatesMutex.Unlock()
+	if len(names) == 0 {
+		renderOnly = nil
+		return
+	}
+	renderOnly = make(map[string]bool)
+	for _, name := range names {
+		renderOnly[name] = true
+	}
+}
+
+func skipTemplate(name string) bool {
+	templatesMutex.Lock()
+	defer templatesMutex.Unlock()
+	return renderOnly != nil && !renderOnly[name]
+}
+
+// ResetTemplates clears the template cache, so that changed template files are picked up.
+func ResetTemplates() {
+	templatesMutex.Lock()
+	defer templatesMutex.Unlock()
+	templates = make(map[string]*template.Template)
+}
+
+func loadTemplate(name string, locale i18n.Locale) (*template.Template, error) {
+	templatesMutex.Lock()
+	defer templatesMutex.Unlock()
 
-func loadTemplate(name string, basePath string) (*template.Template, error) {
-	if t, ok := templates[name]; ok {
+	key := fmt.Sprintf("%s@%s", name, locale)
+	if t, ok := templates[key]; ok {
 		return t, nil
 	}
 
@@ -28,30 +65,45 @@ func loadTemplate(name string, basePath string) (*template.Template, error) {
 	files := make([]string, 0, 1+len(parts))
 	files = append(files, fmt.Sprintf("templates/%s.html", name))
 	files = append(files, parts...)
+	// BasePath makes a path root-relative
+	basePathFunc := func(p string) string {
+		if !strings.HasPrefix(p, "/") {
+			return "/" + p
+		}
+		return p
+	}
 	t, err := template.New(name + ".html").Funcs(template.FuncMap{
-		"BasePath": func(p string) string {
-			res := basePath
-			if !strings.HasPrefix(p, "/") {
-				res += "/"
-			}
-			res += p
-			if strings.HasPrefix(basePath, "/Users/") && strings.HasSuffix(p, "/") {
-				res += "index.html"
-			}
-			return res
+		"BasePath": basePathFunc,
+		// LocalPath is BasePath for pages that exist in every locale
+		"LocalPath": func(p string) string {
+			return basePathFunc(locale.Path(p))
+		},
+		"Locale": func() i18n.Locale {
+			return locale
 		},
+		"T": locale.T,
+		// TH is T for (trusted) messages containing HTML markup
+		"TH": func(key string, args ...any) template.HTML {
+			return template.HTML(locale.T(key, args...))
+		},
+		"Date": func(tr TimeRange) string {
+			return tr.Localized(locale)
+		},
+		"Month": locale.FormatMonth,
+		// asset returns the hashed path and the integrity value of an asset, e.g. {{with asset "main.js"}}{{.Path}} {{.Integrity}}{{end}}
+		"asset": LookupAsset,
 	}).ParseFiles(files...)
 	if err != nil {
 		return nil, err
 	}
 
-	templates[name] = t
+	templates[key] = t
 	return t, nil
 }
 
-func executeTemplateToBuffer(templateName string, basePath string, data any) (*bytes.Buffer, error) {
+func executeTemplateToBuffer(templateName string, locale i18n.Locale, data any) (*bytes.Buffer, error) {
 	// load template
-	templ, err := loadTemplate(templateName, basePath)
+	templ, err := loadTemplate(templateName, locale)
 	if err != nil {
 		return nil, err
 	}
@@ -66,63 +118,40 @@ func executeTemplateToBuffer(templateName string, basePath string, data any) (*b
 	return &buffer, nil
 }
 
-func prepareOutputFile(fileName string) (*os.File, error) {
-	// create output folder + file
-	outDir := filepath.Dir(fileName)
-	err := MakeDir(outDir)
-	if err != nil {
-		return nil, err
-	}
-
-	// create output file
-	out, err := os.Create(fileName)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func ExecuteTemplate(templateName string, fileName string, data any) error {
+	return ExecuteTemplateLocale(templateName, fileName, i18n.Default, data)
 }
 
-func ExecuteTemplate(templateName string, fileName string, basePath string, data any) error {
-	buffer, err := executeTemplateToBuffer(templateName, basePath, data)
-	if err != nil {
-		return fmt.Errorf("render template: %w", err)
+func ExecuteTemplateLocale(templateName string, fileName string, locale i18n.Locale, data any) error {
+	if skipTemplate(templateName) {
+		return nil
 	}
-
-	out, err := prepareOutputFile(fileName)
+	buffer, err := executeTemplateToBuffer(templateName, locale, data)
 	if err != nil {
-		return fmt.Errorf("prepare output file: %w", err)
+		return fmt.Errorf("render template: %w", err)
 	}
-	defer out.Close()
 
-	// minify buffer to output file
+	// minify buffer
+	var minified bytes.Buffer
 	m := minify.New()
 	m.AddFunc("text/css", html.Minify)
 	m.Add("text/html", &html.Minifier{KeepQuotes: true})
-	err = m.Minify("text/html", out, buffer)
+	err = m.Minify("text/html", &minified, buffer)
 	if err != nil {
 		return fmt.Errorf("minifying html output: %w", err)
 	}
 
-	return nil
+	return writePage(fileName, minified.Bytes())
 }
 
-func ExecuteTemplateNoMinify(templateName string, fileName string, basePath string, data any) error {
-	buffer, err := executeTemplateToBuffer(templateName, basePath, data)
-	if err != nil {
-		return fmt.Errorf("render template: %w", err)
-	}
-
-	out, err := prepareOutputFile(fileName)
-	if err != nil {
-		return fmt.Errorf("prepare output file: %w", err)
+func ExecuteTemplateNoMinify(templateName string, fileName string, data any) error {
+	if skipTemplate(templateName) {
+		return nil
 	}
-	defer out.Close()
-
-	// write buffer to output file
-	_, err = out.Write(buffer.Bytes())
+	buffer, err := executeTemplateToBuffer(templateName, i18n.Default, data)
 	if err != nil {
-		return fmt.Errorf("write buffer to output file: %w", err)
+		return fmt.Errorf("render template: %w", err)
 	}
 
-	return nil
+	return writePage(fileName, buffer.Bytes())
 }
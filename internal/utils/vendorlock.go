@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VendorFile is a single vendored file: Url may contain "{version}", Target is relative to the vendor dir. Resolved is
+// the URL the checksum was recorded for, so a version bump without a download is detected offline.
+type VendorFile struct {
+	Url      string `json:"url"`
+	Target   string `json:"target"`
+	Sha256   string `json:"sha256"`
+	Resolved string `json:"resolved"`
+}
+
+// VendorPackage is a vendored package; Datasource and Package are used by renovate to look up new versions.
+type VendorPackage struct {
+	Datasource string       `json:"datasource,omitempty"`
+	Package    string       `json:"package"`
+	Version    string       `json:"version"`
+	Files      []VendorFile `json:"files"`
+}
+
+// VendorLock pins the versions and the checksums of the vendored files.
+type VendorLock struct {
+	Packages []VendorPackage `json:"packages"`
+}
+
+func LoadVendorLock(path string) (VendorLock, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return VendorLock{}, fmt.Errorf("load vendor lock file '%s': %w", path, err)
+	}
+	var lock VendorLock
+	if err := json.Unmarshal(buf, &lock); err != nil {
+		return VendorLock{}, fmt.Errorf("unmarshall vendor lock data: %w", err)
+	}
+	targets := make(map[string]string)
+	for _, pkg := range lock.Packages {
+		for _, file := range pkg.Files {
+			if file.Url == "" || file.Target == "" {
+				return VendorLock{}, fmt.Errorf("vendor lock: file of '%s' without url or target", pkg.Package)
+			}
+			if other, found := targets[file.Target]; found {
+				return VendorLock{}, fmt.Errorf("vendor lock: target '%s' of '%s' already used by '%s'", file.Target, pkg.Package, other)
+			}
+			targets[file.Target] = pkg.Package
+		}
+	}
+	return lock, nil
+}
+
+func (lock VendorLock) Save(path string) error {
+	return WriteJSON(path, lock)
+}
+
+// Url returns the download URL of file for the version of the package.
+func (pkg VendorPackage) Url(file VendorFile) string {
+	return strings.ReplaceAll(file.Url, "{version}", pkg.Version)
+}
+
+// Sha256File returns the hex encoded SHA-256 of a file.
+func Sha256File(path string) (string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify checks the files in dir against the lock (offline) and returns one error per missing, unpinned or modified
+// file, and per file whose checksum was recorded for another version than the package's.
+func (lock VendorLock) Verify(dir string) []error {
+	errs := make([]error, 0)
+	for _, pkg := range lock.Packages {
+		for _, file := range pkg.Files {
+			target := filepath.Join(dir, filepath.FromSlash(file.Target))
+			if url := pkg.Url(file); file.Resolved != url {
+				errs = append(errs, fmt.Errorf("%s (%s %s): checksum recorded for '%s', not for '%s' (run -relock)", target, pkg.Package, pkg.Version, file.Resolved, url))
+				continue
+			}
+			if file.Sha256 == "" {
+				errs = append(errs, fmt.Errorf("%s (%s %s): no checksum in lock file", target, pkg.Package, pkg.Version))
+				continue
+			}
+			sum, err := Sha256File(target)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s (%s %s): %w", target, pkg.Package, pkg.Version, err))
+				continue
+			}
+			if sum != file.Sha256 {
+				errs = append(errs, fmt.Errorf("%s (%s %s): checksum mismatch: expected sha256 %s, got %s", target, pkg.Package, pkg.Version, file.Sha256, sum))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const helloSha256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" // "hello"
+
+func TestVendorLockVerify(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0770); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "ok.js"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "modified.js"), []byte("hello!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock := VendorLock{[]VendorPackage{{"npm", "pkg", "1.0.0", []VendorFile{
+		{"https://example.com/{version}/ok.js", "pkg/ok.js", helloSha256, "https://example.com/1.0.0/ok.js"},
+		{"https://example.com/{version}/modified.js", "pkg/modified.js", helloSha256, "https://example.com/1.0.0/modified.js"},
+		{"https://example.com/{version}/missing.js", "pkg/missing.js", helloSha256, "https://example.com/1.0.0/missing.js"},
+		{"https://example.com/{version}/unpinned.js", "pkg/ok.js", "", "https://example.com/1.0.0/unpinned.js"},
+		{"https://example.com/{version}/bumped.js", "pkg/ok.js", helloSha256, "https://example.com/0.9.0/bumped.js"}, // version bumped without relock
+	}}}}
+
+	errs := lock.Verify(dir)
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %v", errs)
+	}
+	for i, expected := range []string{"checksum mismatch", "no such file", "no checksum", "run -relock"} {
+		if !strings.Contains(errs[i].Error(), expected) {
+			t.Errorf("error %d: expected '%s', got '%v'", i, expected, errs[i])
+		}
+	}
+
+	if url := lock.Packages[0].Url(lock.Packages[0].Files[0]); url != "https://example.com/1.0.0/ok.js" {
+		t.Errorf("unexpected url: %s", url)
+	}
+}
+
+func TestDownloadVerified(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	dst := filepath.Join(t.TempDir(), "sub", "file.js")
+
+	// mismatch: dst is not written
+	if _, err := DownloadVerified(server.URL, dst, strings.Repeat("0", 64)); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Fatalf("file written despite checksum mismatch: %v", err)
+	}
+
+	sum, err := DownloadVerified(server.URL, dst, helloSha256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != helloSha256 {
+		t.Errorf("unexpected checksum: %s", sum)
+	}
+	if buf, err := os.ReadFile(dst); err != nil || string(buf) != "hello" {
+		t.Errorf("unexpected content: %q %v", buf, err)
+	}
+
+	// self-signed certificates are rejected
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+	if _, err := DownloadVerified(tlsServer.URL, dst, ""); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("expected certificate error, got %v", err)
+	}
+}
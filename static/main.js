@@ -17,6 +17,52 @@ var toggle_menuitem = function (id) {
     }
 };
 
+// the page language is set by the generator, German is the default
+const lang = document.documentElement.lang || "de";
+
+const messages = {
+    "en": {
+        "Veranstaltung": "Event",
+        "Veranstaltungen": "Events",
+        "vergangene Veranstaltung": "past event",
+        "Vergangene Veranstaltungen": "Past events",
+        "Lauftreff": "Running group",
+        "Lauftreffs": "Running groups",
+        "Lauf-Shop": "Running shop",
+        "Lauf-Shops": "Running shops",
+        "25km um Heidelberg": "25km around Heidelberg",
+        "50km um Heidelberg": "50km around Heidelberg",
+        "Legende": "Legend",
+        "Treffpunkt / Zielbereich": "Meeting point / finish area",
+        "Eintrag": "entry",
+        "Einträge": "entries",
+        "angezeigt": "shown",
+        "über Filter versteckt": "hidden by filters",
+        "über %s versteckt": "hidden by %s",
+        "Kategorien": "categories",
+        "Zum Kalender hinzufügen": "Add to calendar",
+        "Da genaue Start- & End-Zeiten unbekannt sind, werden Events als Ganztages-Einträge angelegt.": "As exact start and end times are unknown, events are added as all-day entries.",
+        "Outlook, Apple Calendar & andere (.ics)": "Outlook, Apple Calendar & others (.ics)",
+        "weitere Treffer": "more results",
+        "Keine Treffer": "No results",
+    },
+};
+
+const tr = function (s) {
+    const m = messages[lang];
+    if (m !== undefined && m[s] !== undefined) {
+        return m[s];
+    }
+    return s;
+};
+
+const localPath = function (slug) {
+    if (lang !== "de") {
+        return `/${lang}/${slug}`;
+    }
+    return `/${slug}`;
+};
+
 const parseGeo = function (s) {
     const re1 = /\s*N\s*(?<lat>\d+\.\d+)\s+E\s*(?<lng>\d+\.\d+)\s*$/gm;
     const match1 = re1.exec(s);
@@ -37,7 +83,7 @@ const parseGeo = function (s) {
     return null;
 };
 
-const loadMap = function (id) {
+const initMap = function (id) {
     var map = L.map(id, {gestureHandling: true}).setView([49.410038, 8.692926], 15);
 
     L.tileLayer('https://tile.openstreetmap.org/{z}/{x}/{y}.png', {
@@ -58,78 +104,69 @@ const loadMap = function (id) {
         radius: 50000
     }).addTo(map).bindPopup("Heidelberg, 50km")
 
-    let blueIcon = load_marker("");
-    let greyIcon = load_marker("grey");
-    let greenIcon = load_marker("green");
-    let redIcon = load_marker("red");
+    return map;
+};
 
-    let markers = [];
-    document.querySelectorAll(".event").forEach(el => {
-        let geo = parseGeo(el.dataset.geo);
-        if (geo !== null) {
-            let icon = null;
-            let zOffset = 0;
-            switch (el.dataset.type) {
-                case "Lauftreff":
-                    zOffset = 1000;
-                    icon = redIcon;
-                    break;
-                case "Lauf-Shop":
-                    zOffset = 1000;
-                    icon = greenIcon;
-                    break;
-                case "vergangene Veranstaltung":
-                    zOffset = -1000;
-                    icon = greyIcon;
-                    break;
-                case "Veranstaltung":
-                default:
-                    zOffset = 1000;
-                    icon = blueIcon;
-                    break;
-            }
+const markerStyle = function (type) {
+    switch (type) {
+        case "Lauftreff":
+            return {icon: load_marker("red"), zIndexOffset: 1000};
+        case "Lauf-Shop":
+            return {icon: load_marker("green"), zIndexOffset: 1000};
+        case "vergangene Veranstaltung":
+            return {icon: load_marker("grey"), zIndexOffset: -1000};
+        case "Veranstaltung":
+        default:
+            return {icon: load_marker(""), zIndexOffset: 1000};
+    }
+};
 
-            let m = L.marker(geo, {icon: icon, zIndexOffset: zOffset});
-            markers.push(m);
-            m.addTo(map);
-            if (el.dataset.time !== undefined) {
-                m.bindPopup(`<a href="/${el.dataset.slug}">${el.dataset.name}</a><br>(${el.dataset.type})<br>${el.dataset.time}<br>${el.dataset.location}`);
-            } else {
-                m.bindPopup(`<a href="/${el.dataset.slug}">${el.dataset.name}</a><br>(${el.dataset.type})<br>${el.dataset.location}`);
-            }
+// name, time and location are raw sheet content: never insert them as HTML
+const markerPopup = function (name, slug, type, time, location) {
+    const popup = createEl("div");
+    const link = createEl("a");
+    link.setAttribute("href", localPath(slug));
+    link.textContent = name;
+    popup.append(link);
+    [`(${tr(type)})`, time, location].forEach(line => {
+        if (line !== undefined && line !== "") {
+            popup.append(createEl("br"), line);
         }
     });
+    return popup;
+};
 
+const addLegend = function (map) {
     const items = [{
-        label: "Veranstaltung",
+        label: tr("Veranstaltung"),
         type: "image",
         url: "/images/marker-icon.png",
     },{
-        label: "vergangene Veranstaltung",
+        label: tr("vergangene Veranstaltung"),
         type: "image",
         url: "/images/marker-grey-icon.png",
     },{
-        label: "Lauftreff",
+        label: tr("Lauftreff"),
         type: "image",
         url: "/images/marker-red-icon.png",
     },{
-        label: "Lauf-Shop",
+        label: tr("Lauf-Shop"),
         type: "image",
         url: "/images/marker-green-icon.png",
     }];
     items.push(
         {
-            label: "25km um Heidelberg",
+            label: tr("25km um Heidelberg"),
             type: "image",
             url: "/images/circle-small.png"
         }, {
-            label: "50km um Heidelberg",
+            label: tr("50km um Heidelberg"),
             type: "image",
             url: "/images/circle-big.png"
         }
     );
     const legend = L.control.Legend({
-        title: "Legende",
+        title: tr("Legende"),
         position: "bottomleft",
         collapsed: true,
         symbolWidth: 30,
@@ -138,9 +175,75 @@ const loadMap = function (id) {
         legends: items
     });
     legend.addTo(map);
+};
+
+const loadMap = function (id) {
+    var map = initMap(id);
+
+    let markers = [];
+    document.querySelectorAll(".event").forEach(el => {
+        let geo = parseGeo(el.dataset.geo);
+        if (geo !== null) {
+            let m = L.marker(geo, markerStyle(el.dataset.type));
+            markers.push(m);
+            m.addTo(map);
+            m.bindPopup(markerPopup(el.dataset.name, el.dataset.slug, el.dataset.type, el.dataset.time, el.dataset.location));
+        }
+    });
+
+    addLegend(map);
 
     var group = new L.featureGroup(markers);
-    map.fitBounds(group.getBounds(), {padding: L.point(40, 40)});
+    map.fitBounds(group.getBounds(), {padding: L.point(40, 40), maxZoom: 14});
+};
+
+const loadGeoJsonMap = function (id) {
+    const el = document.getElementById(id);
+    var map = initMap(id);
+
+    // layers are only fetched once they are shown
+    const layers = [
+        {label: tr("Veranstaltungen"), url: el.dataset.events, active: true},
+        {label: tr("Vergangene Veranstaltungen"), url: el.dataset.eventsOld, active: false},
+        {label: tr("Lauftreffs"), url: el.dataset.groups, active: true},
+        {label: tr("Lauf-Shops"), url: el.dataset.shops, active: true},
+    ];
+
+    let fitted = false;
+    const overlays = {};
+    layers.forEach(layer => {
+        const group = L.featureGroup();
+        let loaded = false;
+        group.on("add", () => {
+            if (loaded) {
+                return;
+            }
+            loaded = true;
+            fetch(layer.url)
+                .then(response => response.json())
+                .then(data => {
+                    L.geoJSON(data, {
+                        pointToLayer: (feature, latlng) => L.marker(latlng, markerStyle(feature.properties.nicetype)),
+                        onEachFeature: (feature, marker) => {
+                            const p = feature.properties;
+                            marker.bindPopup(markerPopup(p.name, p.slug, p.nicetype, p.date, p.location));
+                        },
+                    }).eachLayer(marker => group.addLayer(marker));
+                    if (!fitted && group.getLayers().length > 0) {
+                        fitted = true;
+                        map.fitBounds(group.getBounds(), {padding: L.point(40, 40)});
+                    }
+                })
+                .catch(err => console.error(`failed to load ${layer.url}`, err));
+        });
+        overlays[layer.label] = group;
+        if (layer.active) {
+            group.addTo(map);
+        }
+    });
+
+    L.control.layers(null, overlays, {collapsed: false}).addTo(map);
+    addLegend(map);
 };
 
 const loadParkrunMap = function (id) {
@@ -157,7 +260,7 @@ const loadParkrunMap = function (id) {
 
     let meetingpoint = L.marker([49.401900, 8.664772], {icon: blueIcon});
     meetingpoint.addTo(map);
-    meetingpoint.bindPopup("Treffpunkt / Zielbereich");
+    meetingpoint.bindPopup(tr("Treffpunkt / Zielbereich"));
 };
 
 var load_marker = function (color) {
@@ -252,13 +355,13 @@ var filter = (s, hiddenTags) => {
     if (hidden != 0 || hiddenTag != 0) {
         var hiddenStr = ""
         if (hidden != 0) {
-            hiddenStr = `, ${hidden} ${hidden!=1 ? "Einträge" : "Eintrag"} über Filter versteckt`;
+            hiddenStr = `, ${hidden} ${tr(hidden!=1 ? "Einträge" : "Eintrag")} ${tr("über Filter versteckt")}`;
         }
         var hiddenTagStr = ""
         if (hiddenTag != 0) {
-            hiddenTagStr = `, ${hiddenTag} ${hiddenTag!=1 ? "Einträge" : "Eintrag"} über <a href="/tags.html">Kategorien</a> versteckt`;
+            hiddenTagStr = `, ${hiddenTag} ${tr(hiddenTag!=1 ? "Einträge" : "Eintrag")} ${tr("über %s versteckt").replace("%s", `<a href="${localPath("tags.html")}">${tr("Kategorien")}</a>`)}`;
         }
-        info.innerHTML = `${shown} ${shown!=1 ? "Einträge" : "Eintrag"} angezeigt${hiddenStr}${hiddenTagStr}`;
+        info.innerHTML = `${shown} ${tr(shown!=1 ? "Einträge" : "Eintrag")} ${tr("angezeigt")}${hiddenStr}${hiddenTagStr}`;
         info.classList.remove("is-hidden");
     } else {
         info.classList.add("is-hidden");
@@ -288,6 +391,92 @@ function createEl(tag, classes) {
     return el;
 } 
 
+// SEARCH
+// tokenizes like the generator (internal/search): umlaut folding as in SanitizeName, split at non-alphanumerics
+const searchTokenize = function (s, stop) {
+    return s.toLowerCase()
+        .replace(/ä/g, "ae").replace(/ö/g, "oe").replace(/ü/g, "ue").replace(/ß/g, "ss")
+        .normalize("NFD").replace(/[\u0300-\u036f]/g, "")
+        .split(/[^a-z0-9]+/)
+        .filter(t => t.length >= 2 && !stop.has(t));
+};
+
+// returns the documents matching all query tokens (as prefixes of indexed tokens)
+const searchQuery = function (index, query) {
+    let result = null;
+    searchTokenize(query, index.stop).forEach(term => {
+        const matches = new Set();
+        for (const [token, ids] of Object.entries(index.tokens)) {
+            if (token.startsWith(term)) {
+                ids.forEach(id => matches.add(id));
+            }
+        }
+        result = (result === null) ? matches : new Set([...result].filter(id => matches.has(id)));
+    });
+    if (result === null) {
+        return [];
+    }
+    return [...result].sort((a, b) => a - b).map(id => index.docs[id]);
+};
+
+const initSearch = function (el) {
+    const input = el.querySelector("input");
+    const dropdown = el.querySelector(".dropdown");
+    const content = el.querySelector(".dropdown-content");
+    let index = null;
+
+    const load = () => {
+        if (index !== null) {
+            return Promise.resolve(index);
+        }
+        return fetch(el.dataset.index)
+            .then(response => response.json())
+            .then(data => {
+                data.stop = new Set(data.stop);
+                index = data;
+                return index;
+            });
+    };
+    const update = () => {
+        load().then(index => {
+            const docs = searchQuery(index, input.value);
+            content.innerHTML = "";
+            docs.slice(0, 10).forEach(doc => {
+                const item = createEl("a", "dropdown-item");
+                item.setAttribute("href", localPath(doc.u));
+                const details = [doc.d, doc.p].filter(s => s !== undefined && s !== "").join(", ");
+                // the index holds raw sheet content: never insert it as HTML
+                const name = createEl("strong");
+                name.textContent = doc.n;
+                const info = createEl("span", "is-size-7");
+                info.textContent = details;
+                item.append(name, createEl("br"), info);
+                content.appendChild(item);
+            });
+            if (docs.length > 10) {
+                content.appendChild(createEl("hr", "dropdown-divider"));
+                const more = createEl("p", "dropdown-item is-italic");
+                more.textContent = `${docs.length - 10} ${tr("weitere Treffer")}`;
+                content.appendChild(more);
+            }
+            if (docs.length === 0 && input.value.trim() !== "") {
+                const none = createEl("p", "dropdown-item is-italic");
+                none.textContent = tr("Keine Treffer");
+                content.appendChild(none);
+            }
+            dropdown.classList.toggle("is-active", content.childElementCount > 0);
+        }).catch(err => console.error(`failed to load ${el.dataset.index}`, err));
+    };
+    input.addEventListener("focus", () => { load(); });
+    input.addEventListener("input", update);
+    input.addEventListener("keydown", (e) => {
+        if (e.key === "Escape") {
+            input.value = "";
+            dropdown.classList.remove("is-active");
+        }
+    });
+};
+
 var main = () => {
     // TAG FILTER, LOCAL STORAGE
     var storage = getLocalStorage();
@@ -337,13 +526,19 @@ var main = () => {
         filter("", hiddenTags);
     }
 
+    // SEARCH
+    const search = document.querySelector("#search");
+    if (search !== null && search.dataset.index) {
+        initSearch(search);
+    }
+
     // CALENDARS
     document.querySelectorAll(".calendar-button").forEach(dropdown => {
         dropdown.classList.add("dropdown");
 
         const dropdownTrigger = createEl("div", "dropdown-trigger");
         const dropdownTriggerButton = createEl("button", "button is-text is-small py-1 ml-1");
-        dropdownTriggerButton.innerHTML = "Zum Kalender hinzufügen";
+        dropdownTriggerButton.innerHTML = tr("Zum Kalender hinzufügen");
         dropdownTrigger.appendChild(dropdownTriggerButton);
         dropdown.appendChild(dropdownTrigger);
 
@@ -351,7 +546,7 @@ var main = () => {
         const dropdownContent = createEl("div", "dropdown-content");
 
         const hint = createEl("p", "dropdown-item is-italic");
-        hint.innerHTML = "Da genaue Start- & End-Zeiten unbekannt sind, werden Events als Ganztages-Einträge angelegt.";
+        hint.innerHTML = tr("Da genaue Start- & End-Zeiten unbekannt sind, werden Events als Ganztages-Einträge angelegt.");
         dropdownContent.appendChild(hint);
 
         const div1 = createEl("hr", "dropdown-divider");
@@ -374,7 +569,7 @@ var main = () => {
         ics.setAttribute("data-umami-event", "calendar-click");
         ics.setAttribute("rel", "nofollow");
         ics.setAttribute("target", "_blank");
-        ics.innerHTML = "Outlook, Apple Calendar & andere (.ics)";
+        ics.innerHTML = tr("Outlook, Apple Calendar & andere (.ics)");
         dropdownContent.appendChild(ics);
 
         dropdownMenu.appendChild(dropdownContent);
@@ -387,9 +582,15 @@ var main = () => {
         bigMapId = "big-map";
     } else if (document.querySelector("#serie-map") !== null) {
         bigMapId = "serie-map";
+    } else if (document.querySelector("#city-map") !== null) {
+        bigMapId = "city-map";
     }
     if (bigMapId !== "") {
-        loadMap(bigMapId);
+        if (document.getElementById(bigMapId).dataset.events !== undefined) {
+            loadGeoJsonMap(bigMapId);
+        } else {
+            loadMap(bigMapId);
+        }
     }
 
     const mapShowBtn = document.querySelector("#map-show-btn");